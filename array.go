@@ -1,9 +1,9 @@
 package djson
 
 import (
-	"math"
 	"reflect"
 	"sort"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/volatiletech/null/v8"
@@ -12,6 +12,9 @@ import (
 type DA struct {
 	SeekPointer int
 	Element     []interface{}
+	lazy        *lazySource
+	loadErr     error
+	coercion    Coercion
 }
 
 func NewDA() *DA {
@@ -28,21 +31,32 @@ func (m *DA) PushFront(values interface{}) *DA {
 	return m.Insert(0, values)
 }
 
+// ReplaceAtE works like ReplaceAt but reports ErrNonFiniteFloat under
+// NonFiniteError and ErrInvalidNumber, leaving m unchanged.
+
+func (m *DA) ReplaceAtE(idx int, value interface{}) error {
+	if err := checkValue(value); err != nil {
+		return err
+	}
+
+	m.ReplaceAt(idx, value)
+	return nil
+}
+
 func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return m
 	}
 
+	if m.coercion != CoercionDefault {
+		defer func() { setCoercionOf(m.Element[idx], m.coercion) }()
+	}
+
 	if IsFloatType(value) {
-		switch t := value.(type) {
-		case float32:
-			if !math.IsNaN(float64(t)) && !math.IsInf(float64(t), 0) {
-				m.Element[idx] = t
-			}
-		case float64:
-			if !math.IsNaN(t) && !math.IsInf(float64(t), 0) {
-				m.Element[idx] = t
-			}
+		if v, keep := storedFloat(value); keep {
+			m.Element[idx] = v
 		}
 
 		return m
@@ -53,6 +67,13 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 		return m
 	}
 
+	if n, ok := toNumber(value); ok {
+		if isNumberLexeme(string(n)) {
+			m.Element[idx] = n
+		}
+		return m
+	}
+
 	if n, ok := value.(json.Number); ok {
 		if i, err := n.Int64(); err == nil {
 			m.Element[idx] = i
@@ -71,6 +92,26 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 		} else {
 			m.Element[idx] = ""
 		}
+	case time.Time:
+		m.Element[idx] = encodeTime(t)
+	case *time.Time:
+		if t != nil {
+			m.Element[idx] = encodeTime(*t)
+		} else {
+			m.Element[idx] = nil
+		}
+	case null.Time:
+		if t.Valid {
+			m.Element[idx] = encodeTime(t.Time)
+		} else {
+			m.Element[idx] = nil
+		}
+	case null.Bytes:
+		if t.Valid {
+			m.Element[idx] = encodeBytes(t.Bytes)
+		} else {
+			m.Element[idx] = ""
+		}
 	case null.Bool:
 		if t.Valid {
 			m.Element[idx] = t.Bool
@@ -139,13 +180,17 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 		}
 	case null.Float32:
 		if t.Valid {
-			m.Element[idx] = t.Float32
+			if v, keep := storedFloat(t.Float32); keep {
+				m.Element[idx] = v
+			}
 		} else {
 			m.Element[idx] = float32(0.0)
 		}
 	case null.Float64:
 		if t.Valid {
-			m.Element[idx] = t.Float64
+			if v, keep := storedFloat(t.Float64); keep {
+				m.Element[idx] = v
+			}
 		} else {
 			m.Element[idx] = float64(0.0)
 		}
@@ -169,6 +214,8 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 		m.Element[idx] = t.Interface()
 	case *JSON:
 		m.Element[idx] = t.Interface()
+	case NullJSON:
+		m.Element[idx] = t.Interface()
 	case []string:
 		m.Element[idx] = PremitiveSliceToArray(t)
 	case []bool:
@@ -189,8 +236,8 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 		m.Element[idx] = PremitiveSliceToArray(t)
 	case []uint:
 		m.Element[idx] = PremitiveSliceToArray(t)
-	case []uint8:
-		m.Element[idx] = PremitiveSliceToArray(t)
+	case []byte:
+		m.Element[idx] = encodeBytes(t)
 	case []uint16:
 		m.Element[idx] = PremitiveSliceToArray(t)
 	case []uint32:
@@ -233,10 +280,35 @@ func (m *DA) ReplaceAt(idx int, value interface{}) *DA {
 }
 
 func (m *DA) Insert(idx int, value interface{}) *DA {
+	m.load()
+
 	if idx > m.Size() || idx < 0 {
 		idx = m.Size()
 	}
 
+	switch t := value.(type) {
+	case null.Float32:
+		if t.Valid {
+			value = t.Float32
+		}
+	case null.Float64:
+		if t.Valid {
+			value = t.Float64
+		}
+	}
+
+	if IsFloatType(value) {
+		v, keep := storedFloat(value)
+		if !keep {
+			return m
+		}
+		value = v
+	}
+
+	if n, ok := toNumber(value); ok && !isNumberLexeme(string(n)) {
+		return m
+	}
+
 	if idx == m.Size() { // back
 		m.Element = append(m.Element, nil)
 	} else {
@@ -246,15 +318,34 @@ func (m *DA) Insert(idx int, value interface{}) *DA {
 	return m.ReplaceAt(idx, value)
 }
 
+// InsertE works like Insert but reports ErrNonFiniteFloat under
+// NonFiniteError and ErrInvalidNumber, leaving m unchanged.
+
+func (m *DA) InsertE(idx int, value interface{}) error {
+	if err := checkValue(value); err != nil {
+		return err
+	}
+
+	m.Insert(idx, value)
+	return nil
+}
+
 func (m *DA) PutArray(value interface{}) *DA {
 	m.Insert(m.Size(), value)
 	return m
 }
 
+// PutArrayE works like PutArray but reports errors like InsertE.
+
+func (m *DA) PutArrayE(value interface{}) error {
+	return m.InsertE(m.Size(), value)
+}
+
 func (m *DA) Put(v interface{}) *DA {
 
 	switch t := v.(type) {
 	case *DA:
+		t.load()
 		for idx := range t.Element {
 			m.Insert(m.Size(), t.Element[idx])
 		}
@@ -294,10 +385,8 @@ func (m *DA) Put(v interface{}) *DA {
 		for idx := range t {
 			m.Insert(m.Size(), t[idx])
 		}
-	case []uint8:
-		for idx := range t {
-			m.Insert(m.Size(), t[idx])
-		}
+	case []byte:
+		m.Insert(m.Size(), t)
 	case []uint16:
 		for idx := range t {
 			m.Insert(m.Size(), t[idx])
@@ -390,14 +479,20 @@ func (m *DA) Put(v interface{}) *DA {
 }
 
 func (m *DA) Size() int {
+	m.load()
+
 	return len(m.Element)
 }
 
 func (m *DA) Len() int {
+	m.load()
+
 	return len(m.Element)
 }
 
 func (m *DA) Remove(idx int) *DA {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return m
 	}
@@ -407,19 +502,23 @@ func (m *DA) Remove(idx int) *DA {
 }
 
 func (m *DA) Get(idx int) (interface{}, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return nil, false
 	}
 
-	return m.Element[idx], true
+	return loaded(m.Element[idx]), true
 }
 
 func (m *DA) Type(idx int) (string, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return "", false
 	}
 
-	switch m.Element[idx].(type) {
+	switch t := m.Element[idx].(type) {
 	case DA, *DA:
 		return "array", true
 	case DO, *DO:
@@ -428,6 +527,11 @@ func (m *DA) Type(idx int) (string, bool) {
 		return "int", true
 	case float32, float64:
 		return "float", true
+	case Number:
+		if t.IsInt() {
+			return "int", true
+		}
+		return "float", true
 	case string:
 		return "string", true
 	case bool:
@@ -440,10 +544,16 @@ func (m *DA) Type(idx int) (string, bool) {
 }
 
 func (m *DA) Bool(idx int) (bool, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return false, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceBool(m.Element[idx], m.coercion)
+	}
+
 	if boolVal, ok := getBoolBase(m.Element[idx]); ok {
 		return boolVal, true
 	}
@@ -452,10 +562,16 @@ func (m *DA) Bool(idx int) (bool, bool) {
 }
 
 func (m *DA) Float(idx int) (float64, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return 0, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceFloat(m.Element[idx], m.coercion)
+	}
+
 	if floatVal, ok := getFloatBase(m.Element[idx]); ok {
 		return floatVal, true
 	}
@@ -464,10 +580,16 @@ func (m *DA) Float(idx int) (float64, bool) {
 }
 
 func (m *DA) Int(idx int) (int64, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return 0, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceInt(m.Element[idx], m.coercion)
+	}
+
 	if intVal, ok := getIntBase(m.Element[idx]); ok {
 		return intVal, true
 	}
@@ -476,6 +598,8 @@ func (m *DA) Int(idx int) (int64, bool) {
 }
 
 func (m *DA) Object(idx int) (*DO, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return nil, false
 	}
@@ -484,6 +608,7 @@ func (m *DA) Object(idx int) (*DO, bool) {
 	case DO:
 		return &t, true
 	case *DO:
+		loaded(t)
 		return t, true
 	}
 
@@ -491,6 +616,8 @@ func (m *DA) Object(idx int) (*DO, bool) {
 }
 
 func (m *DA) Array(idx int) (*DA, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return nil, false
 	}
@@ -499,6 +626,7 @@ func (m *DA) Array(idx int) (*DA, bool) {
 	case DA:
 		return &t, true
 	case *DA:
+		loaded(t)
 		return t, true
 	}
 
@@ -506,10 +634,17 @@ func (m *DA) Array(idx int) (*DA, bool) {
 }
 
 func (m *DA) String(idx int) string {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return ""
 	}
 
+	if m.coercion != CoercionDefault {
+		s, _ := coerceString(m.Element[idx], m.coercion)
+		return s
+	}
+
 	switch t := m.Element[idx].(type) {
 	case DA:
 		return t.ToString()
@@ -531,10 +666,16 @@ func (m *DA) String(idx int) string {
 }
 
 func (m *DA) String2(idx int) (string, bool) {
+	m.load()
+
 	if idx >= m.Size() || idx < 0 {
 		return "", false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceString(m.Element[idx], m.coercion)
+	}
+
 	switch t := m.Element[idx].(type) {
 	case DA:
 		return t.ToString(), true
@@ -552,16 +693,16 @@ func (m *DA) String2(idx int) (string, bool) {
 }
 
 func (m *DA) ToStringPretty() string {
-	jsonByte, _ := json.MarshalIndent(ArrayToSlice(m), "", "   ")
-	return string(jsonByte)
+	return encodeToString(m, "   ")
 }
 
 func (m *DA) ToString() string {
-	jsonByte, _ := json.Marshal(ArrayToSlice(m))
-	return string(jsonByte)
+	return encodeToString(m, "")
 }
 
 func (m *DA) SortObject(isAsc bool, key string) bool {
+	m.load()
+
 	numElement := len(m.Element)
 
 	if numElement == 0 {
@@ -627,7 +768,7 @@ func (m *DA) SortObject(isAsc bool, key string) bool {
 				iInt, _ := ido.Int(key)
 				jInt, _ := jdo.Int(key)
 				return iInt < jInt
-			case "float32", "float64":
+			case "float32", "float64", "djson.Number":
 				iFloat, _ := ido.Float(key)
 				jFloat, _ := jdo.Float(key)
 				return iFloat < jFloat
@@ -674,7 +815,7 @@ func (m *DA) SortObject(isAsc bool, key string) bool {
 				iInt, _ := ido.Int(key)
 				jInt, _ := jdo.Int(key)
 				return iInt > jInt
-			case "float32", "float64":
+			case "float32", "float64", "djson.Number":
 				iFloat, _ := ido.Float(key)
 				jFloat, _ := jdo.Float(key)
 				return iFloat > jFloat
@@ -692,6 +833,8 @@ func (m *DA) SortObject(isAsc bool, key string) bool {
 }
 
 func (m *DA) SortPrimitive(isAsc bool) bool {
+	m.load()
+
 	numElement := len(m.Element)
 
 	if numElement == 0 {
@@ -742,7 +885,7 @@ func (m *DA) SortPrimitive(isAsc bool) bool {
 					iInt, _ := m.Int(i)
 					jInt, _ := m.Int(j)
 					return iInt < jInt
-				case "float32", "float64":
+				case "float32", "float64", "djson.Number":
 					iFloat, _ := m.Float(i)
 					jFloat, _ := m.Float(j)
 					return iFloat < jFloat
@@ -760,7 +903,7 @@ func (m *DA) SortPrimitive(isAsc bool) bool {
 					iInt, _ := m.Int(i)
 					jInt, _ := m.Int(j)
 					return iInt > jInt
-				case "float32", "float64":
+				case "float32", "float64", "djson.Number":
 					iFloat, _ := m.Float(i)
 					jFloat, _ := m.Float(j)
 					return iFloat < jFloat
@@ -802,6 +945,8 @@ func (m *DA) SortDesc(key ...string) bool {
 }
 
 func (m *DA) Equal(t *DA) bool {
+	m.load()
+
 	if m.Size() != t.Size() {
 		return false
 	}
@@ -843,6 +988,10 @@ func (m *DA) Equal(t *DA) bool {
 			if mFloat != tFloat {
 				return false
 			}
+		case Number:
+			if !equalNumber(m.Element[i].(Number), t.Element[i].(Number)) {
+				return false
+			}
 		case *DO:
 			mdo := m.Element[i].(*DO)
 			tdo := t.Element[i].(*DO)
@@ -871,8 +1020,10 @@ func (m *DA) Equal(t *DA) bool {
 }
 
 func (m *DA) Clone() *DA {
+	m.load()
 
 	t := NewDA()
+	t.coercion = m.coercion
 
 	t.Element = make([]interface{}, m.Size())
 
@@ -886,10 +1037,14 @@ func (m *DA) Clone() *DA {
 			t.Element[i] = m.Element[i].(string)
 		case bool:
 			t.Element[i] = m.Element[i].(bool)
-		case int, uint, int8, uint8, int16, uint16, int32, uint32, int64, uint64:
+		case int, uint, int8, uint8, int16, uint16, int32, uint32, int64:
 			t.Element[i], _ = m.Int(i)
+		case uint64:
+			t.Element[i] = m.Element[i].(uint64)
 		case float32, float64:
 			t.Element[i], _ = m.Float(i)
+		case Number:
+			t.Element[i] = m.Element[i]
 		case *DO:
 			mdo := m.Element[i].(*DO)
 			t.Element[i] = mdo.Clone()
@@ -905,7 +1060,22 @@ func (m *DA) Clone() *DA {
 	return t
 }
 
+func (m *DA) setOrderedDeep(ordered bool) {
+	m.load()
+
+	for _, v := range m.Element {
+		switch t := v.(type) {
+		case *DO:
+			t.setOrderedDeep(ordered)
+		case *DA:
+			t.setOrderedDeep(ordered)
+		}
+	}
+}
+
 func (m *DA) Seek(seekp ...int) {
+	m.load()
+
 	m.SeekPointer = 0
 
 	if len(seekp) > 0 && len(m.Element) > seekp[0] {
@@ -914,10 +1084,14 @@ func (m *DA) Seek(seekp ...int) {
 }
 
 func (m *DA) Next() bool {
+	m.load()
+
 	return len(m.Element) > m.SeekPointer
 }
 
 func (m *DA) Scan() (interface{}, bool) {
+	m.load()
+
 	defer func() {
 		m.SeekPointer++
 	}()
@@ -927,9 +1101,11 @@ func (m *DA) Scan() (interface{}, bool) {
 	}
 
 	ret := m.Element[m.SeekPointer]
-	return ret, true
+	return loaded(ret), true
 }
 
 func (m *DA) Skip() {
+	m.load()
+
 	m.SeekPointer++
 }
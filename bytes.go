@@ -0,0 +1,107 @@
+package djson
+
+import (
+	"encoding/base64"
+)
+
+// bytesEncoding is how []byte and null.Bytes values are stored by Put.
+var bytesEncoding = base64.StdEncoding
+
+// SetBytesEncoding sets the base64 alphabet and padding used to store []byte
+// values put afterwards, for example base64.URLEncoding or
+// base64.RawStdEncoding, and the only one Bytes and Get[[]byte] accept.
+// nil restores base64.StdEncoding. Values are encoded when they are put, so
+// changing the encoding does not re-encode values already stored, and they
+// no longer decode with the new one.
+
+func SetBytesEncoding(enc *base64.Encoding) {
+	if enc == nil {
+		enc = base64.StdEncoding
+	}
+	bytesEncoding = enc
+}
+
+func encodeBytes(b []byte) string {
+	return bytesEncoding.EncodeToString(b)
+}
+
+// decodeBytes reads s with the configured encoding only, so that ordinary
+// strings are not mistaken for binary values.
+
+func decodeBytes(s string) ([]byte, bool) {
+	b, err := bytesEncoding.Strict().DecodeString(s)
+	if err != nil {
+		return nil, false
+	}
+
+	return b, true
+}
+
+// Bytes decodes the base64 STRING at key. It returns false when the value is
+// missing, not a string or not in the SetBytesEncoding encoding.
+
+func (m *DO) Bytes(key string) ([]byte, bool) {
+	m.load()
+
+	if s, ok := m.Map[key].(string); ok {
+		return decodeBytes(s)
+	}
+
+	return nil, false
+}
+
+func (m *DA) Bytes(idx int) ([]byte, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return nil, false
+	}
+
+	if s, ok := m.Element[idx].(string); ok {
+		return decodeBytes(s)
+	}
+
+	return nil, false
+}
+
+// Bytes decodes a base64 STRING document, or the value at an object key or
+// array index when one is given.
+
+func (m *JSON) Bytes(key ...interface{}) ([]byte, bool) {
+	if IsEmptyArg(key) {
+		if m._Type != STRING {
+			return nil, false
+		}
+		return decodeBytes(m._String)
+	}
+
+	switch tkey := key[0].(type) {
+	case string:
+		if m._Type == OBJECT {
+			return m._Object.Bytes(tkey)
+		}
+	default:
+		kint, ok := getIntBase(key[0])
+		if ok && m._Type == ARRAY {
+			return m._Array.Bytes(int(kint))
+		}
+	}
+
+	return nil, false
+}
+
+func (m *JSON) BytesPath(path string) ([]byte, bool) {
+	var ret []byte
+	var kok bool
+
+	pok := m.DoPathFunc(path, nil,
+		func(da *DA, idx int, v interface{}) {
+			ret, kok = da.Bytes(idx)
+		},
+		func(do *DO, key string, v interface{}) {
+			ret, kok = do.Bytes(key)
+		},
+	)
+
+	return ret, pok && kok
+}
@@ -0,0 +1,114 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/base64"
+	"testing"
+
+	"github.com/volatiletech/null/v8"
+)
+
+func TestPutBytes(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 'h', 'i'}
+
+	mJson := New().Put(Object{"raw": data, "list": Array{data, 1}})
+	mJson.Put("empty", []byte{})
+	mJson.Put("nb", null.BytesFrom(data))
+	mJson.Put("invalid", null.Bytes{})
+
+	expected := `{"empty":"","invalid":"","list":["+/8AaGk=",1],"nb":"+/8AaGk=","raw":"+/8AaGk="}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	aJson := New().Put(Array{1})
+	aJson.Put(data)
+	if result := aJson.ToString(); result != `[1,"+/8AaGk="]` {
+		t.Errorf("Expected the bytes as one element, but got %s", result)
+	}
+
+	if result := New().Put(data).ToString(); result != "+/8AaGk=" {
+		t.Errorf("Expected a STRING document, but got %s", result)
+	}
+
+	if b, ok := mJson.Bytes("raw"); !ok || !bytes.Equal(b, data) {
+		t.Errorf("Expected %v, but got %v", data, b)
+	}
+
+	if b, ok := mJson.BytesPath(`[list][0]`); !ok || !bytes.Equal(b, data) {
+		t.Errorf("Expected %v, but got %v", data, b)
+	}
+
+	if _, ok := mJson.BytesPath(`[list][1]`); ok {
+		t.Errorf("Expected a number not to decode")
+	}
+
+	if _, ok := New().Put(Object{"s": "not base64!"}).Bytes("s"); ok {
+		t.Errorf("Expected invalid base64 not to decode")
+	}
+}
+
+func TestBytesEncoding(t *testing.T) {
+	data := []byte{0xfb, 0xff, 0x00, 'h', 'i'}
+
+	SetBytesEncoding(base64.RawURLEncoding)
+	defer SetBytesEncoding(nil)
+
+	mJson := New().Put(Object{"raw": data})
+	if result := mJson.ToString(); result != `{"raw":"-_8AaGk"}` {
+		t.Errorf("Expected URL-safe raw base64, but got %s", result)
+	}
+
+	if b, ok := New().Parse(`{"raw":"-_8AaGk"}`).Bytes("raw"); !ok || !bytes.Equal(b, data) {
+		t.Errorf("Expected %v, but got %v", data, b)
+	}
+
+	for _, doc := range []string{`{"raw":"+/8AaGk="}`, `{"raw":"-_8AaGk="}`} {
+		if b, ok := New().Parse(doc).Bytes("raw"); ok {
+			t.Errorf("%s: Expected another alphabet not to decode, but got %v", doc, b)
+		}
+	}
+}
+
+func TestBytesOnlyConfiguredEncoding(t *testing.T) {
+	mJson := New().Parse(`{"word":"abc","pad":"YWJj"}`)
+
+	if b, err := Get[[]byte](mJson, `[word]`); err == nil {
+		t.Errorf("Expected a plain string not to decode, but got %v", b)
+	}
+	if b, err := Get[[]byte](mJson, `[pad]`); err != nil || string(b) != "abc" {
+		t.Errorf("Expected abc, but got %v (%v)", b, err)
+	}
+}
+
+func TestBytesFields(t *testing.T) {
+	type Blob struct {
+		Name  string     `json:"name"`
+		Data  []byte     `json:"data"`
+		Thumb null.Bytes `json:"thumb"`
+		Parts [][]byte   `json:"parts"`
+	}
+
+	in := Blob{Name: "a", Data: []byte("hello"), Thumb: null.BytesFrom([]byte{1, 2}), Parts: [][]byte{[]byte("x")}}
+
+	mJson := New()
+	mJson.FromFields(in)
+
+	expected := `{"data":"aGVsbG8=","name":"a","parts":["eA=="],"thumb":"AQI="}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	var out Blob
+	mJson.ToFields(&out)
+
+	if out.Name != "a" || string(out.Data) != "hello" || !out.Thumb.Valid || !bytes.Equal(out.Thumb.Bytes, []byte{1, 2}) {
+		t.Errorf("Expected %v, but got %v", in, out)
+	}
+
+	mJson = New()
+	mJson.FromFields(map[string]interface{}{"data": []byte("hi"), "thumb": null.BytesFrom([]byte("yo"))})
+	if result := mJson.ToString(); result != `{"data":"aGk=","thumb":"eW8="}` {
+		t.Errorf("Expected map bytes to be encoded, but got %s", result)
+	}
+}
@@ -0,0 +1,263 @@
+package djson
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// ErrUnsupportedCanonical is returned by CanonicalE for a value with no JSON
+// form, such as a channel stored in Map directly.
+var ErrUnsupportedCanonical = errors.New("djson: value has no canonical form")
+
+// Canonical returns the RFC 8785 (JSON Canonicalization Scheme) form of the
+// document: object keys sorted by UTF-16 code units, numbers formatted as
+// ECMAScript doubles and strings with minimal escaping.
+// Integers are converted to IEEE 754 doubles as the scheme requires, so
+// magnitudes above 2^53 lose precision exactly like in JavaScript.
+// Unlike ToString, a STRING document is quoted.
+// Canonical returns nil where CanonicalE fails.
+
+func (m *JSON) Canonical() []byte {
+	b, _ := m.CanonicalE()
+	return b
+}
+
+// CanonicalE works like Canonical but reports values the scheme rejects:
+// ErrNonFiniteFloat for NaN, ±Inf and numbers beyond the double range,
+// ErrInvalidNumber for a malformed Number and ErrUnsupportedCanonical for
+// anything else without a JSON form.
+
+func (m *JSON) CanonicalE() ([]byte, error) {
+	return canonical(m.Interface())
+}
+
+func (m *DO) Canonical() []byte {
+	b, _ := m.CanonicalE()
+	return b
+}
+
+func (m *DO) CanonicalE() ([]byte, error) {
+	return canonical(m)
+}
+
+func (m *DA) Canonical() []byte {
+	b, _ := m.CanonicalE()
+	return b
+}
+
+func (m *DA) CanonicalE() ([]byte, error) {
+	return canonical(m)
+}
+
+func canonical(v interface{}) ([]byte, error) {
+	b, err := appendCanonical(make([]byte, 0, 256), v)
+	if err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+func appendCanonical(buf []byte, v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case nil:
+		return append(buf, "null"...), nil
+	case string:
+		return appendCanonicalString(buf, t), nil
+	case bool:
+		return strconv.AppendBool(buf, t), nil
+	case int, int8, int16, int32, int64:
+		return appendES6Number(buf, float64(reflect.ValueOf(t).Int()))
+	case uint, uint8, uint16, uint32, uint64:
+		return appendES6Number(buf, float64(reflect.ValueOf(t).Uint()))
+	case float32:
+		return appendES6Number(buf, float64(t))
+	case float64:
+		return appendES6Number(buf, t)
+	case Number:
+		if !isNumberLexeme(string(t)) {
+			return nil, ErrInvalidNumber
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return nil, ErrNonFiniteFloat
+		}
+		return appendES6Number(buf, f)
+	case DO:
+		return appendCanonicalObject(buf, &t)
+	case *DO:
+		return appendCanonicalObject(buf, t)
+	case DA:
+		return appendCanonicalArray(buf, &t)
+	case *DA:
+		return appendCanonicalArray(buf, t)
+	case *JSON:
+		if t == nil {
+			return append(buf, "null"...), nil
+		}
+		return appendCanonical(buf, t.Interface())
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedCanonical, v)
+}
+
+func appendCanonicalObject(buf []byte, obj *DO) ([]byte, error) {
+	buf = append(buf, '{')
+	if obj == nil {
+		return append(buf, '}'), nil
+	}
+	obj.load()
+
+	keys := make([]string, 0, len(obj.Map))
+	units := make(map[string][]uint16, len(obj.Map))
+	for k := range obj.Map {
+		keys = append(keys, k)
+		units[k] = utf16.Encode([]rune(k))
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		iu, ju := units[keys[i]], units[keys[j]]
+		for k := 0; k < len(iu) && k < len(ju); k++ {
+			if iu[k] != ju[k] {
+				return iu[k] < ju[k]
+			}
+		}
+		return len(iu) < len(ju)
+	})
+
+	var err error
+	for idx, k := range keys {
+		if idx > 0 {
+			buf = append(buf, ',')
+		}
+		buf = appendCanonicalString(buf, k)
+		buf = append(buf, ':')
+		if buf, err = appendCanonical(buf, obj.Map[k]); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(buf, '}'), nil
+}
+
+func appendCanonicalArray(buf []byte, arr *DA) ([]byte, error) {
+	buf = append(buf, '[')
+	if arr == nil {
+		return append(buf, ']'), nil
+	}
+	arr.load()
+
+	var err error
+	for idx := range arr.Element {
+		if idx > 0 {
+			buf = append(buf, ',')
+		}
+		if buf, err = appendCanonical(buf, arr.Element[idx]); err != nil {
+			return nil, err
+		}
+	}
+
+	return append(buf, ']'), nil
+}
+
+func appendCanonicalString(buf []byte, s string) []byte {
+	buf = append(buf, '"')
+
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			buf = utf8.AppendRune(buf, r) // invalid bytes become U+FFFD
+			i += size
+			continue
+		}
+
+		switch c {
+		case '"', '\\':
+			buf = append(buf, '\\', c)
+		case '\b':
+			buf = append(buf, '\\', 'b')
+		case '\f':
+			buf = append(buf, '\\', 'f')
+		case '\n':
+			buf = append(buf, '\\', 'n')
+		case '\r':
+			buf = append(buf, '\\', 'r')
+		case '\t':
+			buf = append(buf, '\\', 't')
+		default:
+			if c < 0x20 {
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			} else {
+				buf = append(buf, c)
+			}
+		}
+		i++
+	}
+
+	return append(buf, '"')
+}
+
+// appendES6Number implements Number.prototype.toString for finite doubles.
+// Non-finite values have no JSON form and fail with ErrNonFiniteFloat.
+
+func appendES6Number(buf []byte, f float64) ([]byte, error) {
+	if isNonFinite(f) {
+		return nil, ErrNonFiniteFloat
+	}
+
+	if f == 0 {
+		return append(buf, '0'), nil // also -0
+	}
+
+	if f < 0 {
+		buf = append(buf, '-')
+		f = -f
+	}
+
+	// shortest round-trip digits: d.ddddde±x
+	sci := strconv.FormatFloat(f, 'e', -1, 64)
+	epos := strings.IndexByte(sci, 'e')
+	digits := strings.Replace(sci[:epos], ".", "", 1)
+	exp, _ := strconv.Atoi(sci[epos+1:])
+
+	k := len(digits)
+	n := exp + 1
+
+	switch {
+	case k <= n && n <= 21:
+		buf = append(buf, digits...)
+		for i := 0; i < n-k; i++ {
+			buf = append(buf, '0')
+		}
+	case 0 < n && n <= 21:
+		buf = append(buf, digits[:n]...)
+		buf = append(buf, '.')
+		buf = append(buf, digits[n:]...)
+	case -6 < n && n <= 0:
+		buf = append(buf, '0', '.')
+		for i := 0; i < -n; i++ {
+			buf = append(buf, '0')
+		}
+		buf = append(buf, digits...)
+	default:
+		buf = append(buf, digits[0])
+		if k > 1 {
+			buf = append(buf, '.')
+			buf = append(buf, digits[1:]...)
+		}
+		buf = append(buf, 'e')
+		if n-1 >= 0 {
+			buf = append(buf, '+')
+		}
+		buf = strconv.AppendInt(buf, int64(n-1), 10)
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,94 @@
+package djson
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestCanonical(t *testing.T) {
+	jsonDoc := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001],
+		"string": "\u20ac$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+
+	mJson := New().Parse(jsonDoc)
+
+	expected := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	if result := string(mJson.Canonical()); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestCanonicalKeyOrder(t *testing.T) {
+	mJson := New().Parse(`{"\u20ac":"Euro Sign","\r":"Carriage Return","\ufb33":"Hebrew Letter Dalet With Dagesh","1":"One","\ud83d\ude00":"Emoji: Grinning Face","\u0080":"Control","\u00f6":"Latin Small Letter O With Diaeresis"}`)
+
+	expected := "{\"\\r\":\"Carriage Return\",\"1\":\"One\",\"\u0080\":\"Control\",\"\u00f6\":\"Latin Small Letter O With Diaeresis\",\"\u20ac\":\"Euro Sign\",\"\U0001F600\":\"Emoji: Grinning Face\",\"\ufb33\":\"Hebrew Letter Dalet With Dagesh\"}"
+	if result := string(mJson.Canonical()); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestCanonicalNumbers(t *testing.T) {
+	cases := map[uint64]string{
+		0x0000000000000000: "0",
+		0x8000000000000000: "0",
+		0x0000000000000001: "5e-324",
+		0x8000000000000001: "-5e-324",
+		0x7fefffffffffffff: "1.7976931348623157e+308",
+		0xffefffffffffffff: "-1.7976931348623157e+308",
+		0x4340000000000000: "9007199254740992",
+		0xc340000000000000: "-9007199254740992",
+		0x4430000000000000: "295147905179352830000",
+		0x44b52d02c7e14af5: "9.999999999999997e+22",
+		0x44b52d02c7e14af6: "1e+23",
+		0x3eb0c6f7a0b5ed8d: "0.000001",
+		0x3eb0c6f7a0b5ed8c: "9.999999999999997e-7",
+		0x41b3de4355555555: "333333333.3333333",
+		0x41dfffffffc00000: "2147483647",
+	}
+
+	for bits, expected := range cases {
+		if result, _ := appendES6Number(nil, math.Float64frombits(bits)); string(result) != expected {
+			t.Errorf("%016x: Expected %s, but got %s", bits, expected, string(result))
+		}
+	}
+
+	if result := string(NewArray(int64(1)<<53+1, 28).Canonical()); result != `[9007199254740992,28]` {
+		t.Errorf("Expected [9007199254740992,28], but got %s", result)
+	}
+
+	if result := string(NewString("a<b").Canonical()); result != `"a<b"` {
+		t.Errorf(`Expected "a<b", but got %s`, result)
+	}
+}
+
+func TestCanonicalRejects(t *testing.T) {
+	tests := []struct {
+		value    interface{}
+		expected error
+	}{
+		{math.NaN(), ErrNonFiniteFloat},
+		{float32(math.Inf(-1)), ErrNonFiniteFloat},
+		{Number("1e400"), ErrNonFiniteFloat},
+		{Number("abc"), ErrInvalidNumber},
+		{make(chan int), ErrUnsupportedCanonical},
+	}
+
+	for idx, test := range tests {
+		mJson := New().Put(Object{"ok": 1, "nested": Array{1}})
+		mJson._Object.Map["nested"].(*DA).Element[0] = test.value
+
+		if b, err := mJson.CanonicalE(); !errors.Is(err, test.expected) || b != nil {
+			t.Errorf("%d: Expected %v, but got %s (%v)", idx, test.expected, b, err)
+		}
+		if b := mJson.Canonical(); b != nil {
+			t.Errorf("%d: Expected nil, but got %s", idx, b)
+		}
+	}
+
+	if b, err := New().Parse(`[1,null]`).CanonicalE(); err != nil || string(b) != `[1,null]` {
+		t.Errorf("Expected [1,null], but got %s (%v)", b, err)
+	}
+}
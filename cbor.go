@@ -0,0 +1,678 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"sort"
+	"time"
+	"unicode/utf8"
+)
+
+// CBOR (RFC 8949) mapping:
+//
+//	unsigned, negative int <-> INT; unsigned values beyond int64 stay uint64,
+//	                          negative ones decode to a Number
+//	tag 2/3 bignum         <-> Number; integral Numbers beyond 64 bits encode as bignums
+//	float16/32/64          <-> FLOAT, written in the shortest exact width; NaN and
+//	                          ±Inf decode as null or a string under NonFiniteNull
+//	                          and NonFiniteString and fail with ErrNonFiniteFloat
+//	                          otherwise
+//	text string            <-> STRING
+//	byte string             -> STRING holding the bytes in the SetBytesEncoding base64
+//	tag 0/1 time            -> STRING in RFC 3339 form, UTC for epoch times
+//	array, map             <-> DA, DO; non-string map keys are formatted as text
+//	false, true, null      <-> BOOL, NULL; undefined decodes to NULL
+//
+// Indefinite-length items are accepted on input; output always uses definite
+// lengths. Any other tag or simple value is rejected with an error.
+
+var (
+	ErrInvalidCBOR        = errors.New("djson: invalid CBOR data")
+	ErrUnsupportedCBORTag = errors.New("djson: unsupported CBOR tag")
+	ErrUnsupportedCBOR    = errors.New("djson: value has no CBOR form")
+)
+
+const cborMaxDepth = 10000
+
+// MarshalCBOR returns the CBOR encoding of the document, with object keys in
+// the order Keys reports them.
+
+func (m *JSON) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m.Interface(), false)
+}
+
+// MarshalCBOR returns the CBOR encoding of the object.
+
+func (m *DO) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m, false)
+}
+
+// MarshalCBOR returns the CBOR encoding of the array.
+
+func (m *DA) MarshalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m, false)
+}
+
+// CanonicalCBOR returns the core deterministic encoding of RFC 8949 section
+// 4.2.1: shortest forms everywhere and map keys sorted by their encoded bytes.
+
+func (m *JSON) CanonicalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m.Interface(), true)
+}
+
+// CanonicalCBOR returns the core deterministic encoding of the object.
+
+func (m *DO) CanonicalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m, true)
+}
+
+// CanonicalCBOR returns the core deterministic encoding of the array.
+
+func (m *DA) CanonicalCBOR() ([]byte, error) {
+	return appendCBOR(make([]byte, 0, 256), m, true)
+}
+
+// ParseCBOR decodes exactly one CBOR data item into m.
+
+func (m *JSON) ParseCBOR(data []byte) (*JSON, error) {
+	if m._Type != NULL {
+		return m, ErrNotNull
+	}
+
+	v, err := decodeCBOR(data, m._Ordered)
+	if err != nil {
+		return m, err
+	}
+
+	m.setElement(v)
+	return m, nil
+}
+
+// UnmarshalCBOR replaces the object with the CBOR map in data.
+
+func (m *DO) UnmarshalCBOR(data []byte) error {
+	v, err := decodeCBOR(data, m.ordered)
+	if err != nil {
+		return err
+	}
+
+	obj, ok := v.(*DO)
+	if !ok {
+		return fmt.Errorf("%w: data item is not a map", ErrInvalidCBOR)
+	}
+
+	*m = *obj
+	return nil
+}
+
+// UnmarshalCBOR replaces the array with the CBOR array in data.
+
+func (m *DA) UnmarshalCBOR(data []byte) error {
+	v, err := decodeCBOR(data, orderedObject)
+	if err != nil {
+		return err
+	}
+
+	arr, ok := v.(*DA)
+	if !ok {
+		return fmt.Errorf("%w: data item is not an array", ErrInvalidCBOR)
+	}
+
+	*m = *arr
+	return nil
+}
+
+type cborReader struct {
+	data    []byte
+	pos     int
+	depth   int
+	ordered bool
+}
+
+func decodeCBOR(data []byte, ordered bool) (interface{}, error) {
+	d := &cborReader{data: data, ordered: ordered}
+
+	v, err := d.value()
+	if err != nil {
+		return nil, err
+	}
+
+	if d.pos < len(d.data) {
+		return nil, d.errorf("%d trailing bytes", len(d.data)-d.pos)
+	}
+
+	return v, nil
+}
+
+func (d *cborReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidCBOR, fmt.Sprintf(format, args...), d.pos)
+}
+
+// head reads an initial byte and its argument. indefinite is set for
+// additional information 31.
+
+func (d *cborReader) head() (major byte, arg uint64, indefinite bool, err error) {
+	if d.pos >= len(d.data) {
+		return 0, 0, false, d.errorf("unexpected end of data")
+	}
+
+	c := d.data[d.pos]
+	d.pos++
+	major, info := c>>5, c&0x1f
+
+	switch {
+	case info < 24:
+		return major, uint64(info), false, nil
+	case info == 31:
+		return major, 0, true, nil
+	case info > 27:
+		return 0, 0, false, d.errorf("reserved additional information %d", info)
+	}
+
+	size := 1 << (info - 24)
+	if d.pos+size > len(d.data) {
+		return 0, 0, false, d.errorf("unexpected end of data")
+	}
+
+	b := d.data[d.pos : d.pos+size]
+	d.pos += size
+
+	switch size {
+	case 1:
+		arg = uint64(b[0])
+	case 2:
+		arg = uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		arg = uint64(binary.BigEndian.Uint32(b))
+	default:
+		arg = binary.BigEndian.Uint64(b)
+	}
+
+	return major, arg, false, nil
+}
+
+func (d *cborReader) enter() error {
+	d.depth++
+	if d.depth > cborMaxDepth {
+		return d.errorf("nesting deeper than %d", cborMaxDepth)
+	}
+	return nil
+}
+
+func (d *cborReader) isBreak() bool {
+	if d.pos < len(d.data) && d.data[d.pos] == 0xff {
+		d.pos++
+		return true
+	}
+	return false
+}
+
+// bytes reads the payload of a byte or text string, joining the chunks of an
+// indefinite-length one.
+
+func (d *cborReader) bytes(major byte, n uint64, indefinite bool) ([]byte, error) {
+	if !indefinite {
+		if n > uint64(len(d.data)-d.pos) {
+			return nil, d.errorf("length %d exceeds the remaining data", n)
+		}
+		b := d.data[d.pos : d.pos+int(n)]
+		d.pos += int(n)
+		return b, nil
+	}
+
+	var buf []byte
+	for !d.isBreak() {
+		cmajor, cn, cindef, err := d.head()
+		if err != nil {
+			return nil, err
+		}
+		if cmajor != major || cindef {
+			return nil, d.errorf("invalid chunk in indefinite-length string")
+		}
+
+		chunk, err := d.bytes(major, cn, false)
+		if err != nil {
+			return nil, err
+		}
+		buf = append(buf, chunk...)
+	}
+
+	return buf, nil
+}
+
+func (d *cborReader) value() (interface{}, error) {
+	start := d.pos
+
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	if indefinite && (major == 0 || major == 1 || major == 6) {
+		return nil, d.errorf("indefinite length is not allowed for major type %d", major)
+	}
+
+	switch major {
+	case 0:
+		if arg > math.MaxInt64 {
+			return arg, nil
+		}
+		return int64(arg), nil
+	case 1:
+		if arg > math.MaxInt64 {
+			n := new(big.Int).SetUint64(arg)
+			return Number(n.Neg(n).Sub(n, big.NewInt(1)).String()), nil
+		}
+		return -1 - int64(arg), nil
+	case 2:
+		b, err := d.bytes(major, arg, indefinite)
+		return encodeBytes(b), err
+	case 3:
+		b, err := d.bytes(major, arg, indefinite)
+		if err != nil {
+			return nil, err
+		}
+		if !utf8.Valid(b) {
+			d.pos = start
+			return nil, d.errorf("invalid UTF-8 in text string")
+		}
+		return string(b), nil
+	case 4:
+		return d.array(arg, indefinite)
+	case 5:
+		return d.mapping(arg, indefinite)
+	case 6:
+		return d.tag(arg, start)
+	}
+
+	// major type 7
+	if indefinite {
+		d.pos = start
+		return nil, d.errorf("unexpected break")
+	}
+
+	switch info := d.data[start] & 0x1f; {
+	case info == 20:
+		return false, nil
+	case info == 21:
+		return true, nil
+	case info == 22 || info == 23:
+		return nil, nil
+	case info == 25:
+		return d.float(float16ToFloat64(uint16(arg)), start)
+	case info == 26:
+		return d.float(float64(math.Float32frombits(uint32(arg))), start)
+	case info == 27:
+		return d.float(math.Float64frombits(arg), start)
+	}
+
+	d.pos = start
+	return nil, d.errorf("unsupported simple value %d", arg)
+}
+
+// float applies the NonFiniteFloatPolicy to NaN and ±Inf instead of letting
+// the document drop them.
+
+func (d *cborReader) float(f float64, start int) (interface{}, error) {
+	if !isNonFinite(f) {
+		return f, nil
+	}
+
+	if v, keep, _ := nonFiniteValue(f, nonFinitePolicy); keep {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w at offset %d", ErrNonFiniteFloat, start)
+}
+
+func (d *cborReader) tag(tag uint64, start int) (interface{}, error) {
+	if tag == 2 || tag == 3 {
+		return d.bignum(tag, start)
+	}
+
+	content, err := d.value()
+	if tag == 1 && errors.Is(err, ErrNonFiniteFloat) {
+		d.pos = start
+		return nil, d.errorf("invalid content for tag %d", tag)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	switch tag {
+	case 0:
+		s, ok := content.(string)
+		if ok {
+			if _, perr := time.Parse(time.RFC3339Nano, s); perr == nil {
+				return s, nil
+			}
+		}
+	case 1:
+		var t time.Time
+		switch v := content.(type) {
+		case int64:
+			t = time.Unix(v, 0)
+		case float64:
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				break
+			}
+			sec, frac := math.Modf(v)
+			t = time.Unix(int64(sec), int64(frac*1e9))
+		}
+		if !t.IsZero() {
+			return t.UTC().Format(time.RFC3339Nano), nil
+		}
+	case 55799: // self-described CBOR
+		return content, nil
+	default:
+		d.pos = start
+		return nil, fmt.Errorf("%w: tag %d at offset %d", ErrUnsupportedCBORTag, tag, start)
+	}
+
+	d.pos = start
+	return nil, d.errorf("invalid content for tag %d", tag)
+}
+
+// bignum reads the content of tag 2 or 3, which must be a byte string.
+
+func (d *cborReader) bignum(tag uint64, start int) (interface{}, error) {
+	if d.pos < len(d.data) && d.data[d.pos]>>5 != 2 {
+		d.pos = start
+		return nil, d.errorf("invalid content for tag %d", tag)
+	}
+
+	major, arg, indefinite, err := d.head()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := d.bytes(major, arg, indefinite)
+	if err != nil {
+		return nil, err
+	}
+
+	n := new(big.Int).SetBytes(b)
+	if tag == 3 {
+		n.Neg(n).Sub(n, big.NewInt(1))
+	}
+	if n.IsInt64() {
+		return n.Int64(), nil
+	}
+	return Number(n.String()), nil
+}
+
+func (d *cborReader) array(n uint64, indefinite bool) (*DA, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	if !indefinite && n > uint64(len(d.data)-d.pos) {
+		return nil, d.errorf("count %d exceeds the remaining data", n)
+	}
+
+	arr := NewDA()
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.isBreak() {
+			break
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr.PutArray(v)
+	}
+
+	return arr, nil
+}
+
+func (d *cborReader) mapping(n uint64, indefinite bool) (*DO, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	if !indefinite && n > uint64(len(d.data)-d.pos)/2 {
+		return nil, d.errorf("count %d exceeds the remaining data", n)
+	}
+
+	obj := NewDO()
+	if d.ordered {
+		obj.SetOrdered(true)
+	}
+
+	for i := uint64(0); indefinite || i < n; i++ {
+		if indefinite && d.isBreak() {
+			break
+		}
+
+		keyPos := d.pos
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		switch t := k.(type) {
+		case string:
+			key = t
+		case *DO, *DA:
+			d.pos = keyPos
+			return nil, d.errorf("map key must be a scalar")
+		default:
+			key = encodeToString(t, "")
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.Put(key, v)
+	}
+
+	return obj, nil
+}
+
+func float16ToFloat64(h uint16) float64 {
+	exp, mant := int(h>>10)&0x1f, float64(h&0x3ff)
+
+	var f float64
+	switch exp {
+	case 0:
+		f = math.Ldexp(mant, -24)
+	case 31:
+		f = math.Inf(1)
+		if mant != 0 {
+			f = math.NaN()
+		}
+	default:
+		f = math.Ldexp(mant+1024, exp-25)
+	}
+
+	if h&0x8000 != 0 {
+		return -f
+	}
+	return f
+}
+
+// float16Bits returns the half-precision form of f when it is exact.
+
+func float16Bits(f float32) (uint16, bool) {
+	bits := math.Float32bits(f)
+	sign := uint16(bits>>16) & 0x8000
+	exp, mant := int(bits>>23)&0xff, bits&0x7fffff
+
+	switch {
+	case exp == 0xff && mant == 0:
+		return sign | 0x7c00, true
+	case exp == 0 && mant == 0:
+		return sign, true
+	case exp == 0 || exp == 0xff:
+		return 0, false
+	}
+
+	e := exp - 127
+	switch {
+	case e >= -14 && e <= 15 && mant&0x1fff == 0:
+		return sign | uint16(e+15)<<10 | uint16(mant>>13), true
+	case e >= -24 && e < -14:
+		full, shift := mant|0x800000, uint(-e-1)
+		if full&(1<<shift-1) == 0 {
+			return sign | uint16(full>>shift), true
+		}
+	}
+
+	return 0, false
+}
+
+func appendCBORHead(buf []byte, major byte, n uint64) []byte {
+	major <<= 5
+
+	switch {
+	case n < 24:
+		return append(buf, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(buf, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(n))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, major|27), n)
+}
+
+func appendCBORInt(buf []byte, i int64) []byte {
+	if i < 0 {
+		return appendCBORHead(buf, 1, uint64(^i))
+	}
+	return appendCBORHead(buf, 0, uint64(i))
+}
+
+func appendCBORFloat(buf []byte, f float64) []byte {
+	if math.IsNaN(f) {
+		return append(buf, 0xf9, 0x7e, 0x00)
+	}
+
+	if f32 := float32(f); float64(f32) == f {
+		if h, ok := float16Bits(f32); ok {
+			return binary.BigEndian.AppendUint16(append(buf, 0xf9), h)
+		}
+		return binary.BigEndian.AppendUint32(append(buf, 0xfa), math.Float32bits(f32))
+	}
+
+	return binary.BigEndian.AppendUint64(append(buf, 0xfb), math.Float64bits(f))
+}
+
+func appendCBORNumber(buf []byte, n Number) []byte {
+	i, ok := n.BigInt()
+	if !ok {
+		f, _ := n.Float64()
+		return appendCBORFloat(buf, f)
+	}
+
+	major, tag := byte(0), uint64(2)
+	if i.Sign() < 0 {
+		major, tag = 1, 3
+		i = new(big.Int).Sub(new(big.Int).Neg(i), big.NewInt(1))
+	}
+
+	if i.IsUint64() {
+		return appendCBORHead(buf, major, i.Uint64())
+	}
+
+	b := i.Bytes()
+	buf = appendCBORHead(buf, 6, tag)
+	buf = appendCBORHead(buf, 2, uint64(len(b)))
+	return append(buf, b...)
+}
+
+func appendCBOR(buf []byte, v interface{}, canonical bool) ([]byte, error) {
+	switch t := v.(type) {
+	case nil:
+		return append(buf, 0xf6), nil
+	case bool:
+		if t {
+			return append(buf, 0xf5), nil
+		}
+		return append(buf, 0xf4), nil
+	case string:
+		buf = appendCBORHead(buf, 3, uint64(len(t)))
+		return append(buf, t...), nil
+	case int, int8, int16, int32, int64:
+		return appendCBORInt(buf, reflect.ValueOf(t).Int()), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return appendCBORHead(buf, 0, reflect.ValueOf(t).Uint()), nil
+	case float32:
+		return appendCBORFloat(buf, float64(t)), nil
+	case float64:
+		return appendCBORFloat(buf, t), nil
+	case Number:
+		return appendCBORNumber(buf, t), nil
+	case DO:
+		return appendCBOR(buf, &t, canonical)
+	case DA:
+		return appendCBOR(buf, &t, canonical)
+	case *DO:
+		return appendCBORObject(buf, t, canonical)
+	case *DA:
+		buf = appendCBORHead(buf, 4, uint64(t.Size()))
+		for _, each := range t.Element {
+			var err error
+			if buf, err = appendCBOR(buf, each, canonical); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *JSON:
+		return appendCBOR(buf, t.Interface(), canonical)
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedCBOR, v)
+}
+
+func appendCBORObject(buf []byte, obj *DO, canonical bool) ([]byte, error) {
+	keys := obj.Keys()
+	buf = appendCBORHead(buf, 5, uint64(len(keys)))
+
+	if !canonical {
+		for _, k := range keys {
+			buf = appendCBORHead(buf, 3, uint64(len(k)))
+			buf = append(buf, k...)
+
+			var err error
+			if buf, err = appendCBOR(buf, obj.Map[k], false); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+
+	encoded := make([][]byte, len(keys))
+	for i, k := range keys {
+		encoded[i] = append(appendCBORHead(nil, 3, uint64(len(k))), k...)
+	}
+
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.Slice(idx, func(a, b int) bool {
+		return bytes.Compare(encoded[idx[a]], encoded[idx[b]]) < 0
+	})
+
+	for _, i := range idx {
+		buf = append(buf, encoded[i]...)
+
+		var err error
+		if buf, err = appendCBOR(buf, obj.Map[keys[i]], true); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf, nil
+}
@@ -0,0 +1,213 @@
+package djson
+
+import (
+	"encoding/hex"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestCBORRoundTrip(t *testing.T) {
+	jsonDoc := `{"bool":true,"empty":{},"float":1.1,"int":-1000,"list":[0,23,24,-24,-25,65536,4294967296,-9223372036854775808],"name":"Ricardo Longa","nested":{"한글":["값"]},"nil":null}`
+
+	mJson := New().Parse(jsonDoc)
+
+	data, err := mJson.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	back, err := New().ParseCBOR(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := back.ToString(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	obj, _ := mJson._Object.Object("nested")
+	if data, err = obj.MarshalCBOR(); err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := NewDO()
+	if err := decoded.UnmarshalCBOR(data); err != nil || !decoded.Equal(obj) {
+		t.Errorf("Expected %s, but got %s (%v)", obj.ToString(), decoded.ToString(), err)
+	}
+
+	if err := NewDA().UnmarshalCBOR(data); !errors.Is(err, ErrInvalidCBOR) {
+		t.Errorf("Expected ErrInvalidCBOR, but got %v", err)
+	}
+}
+
+func TestCBOREncoding(t *testing.T) {
+	cases := []struct {
+		value    interface{}
+		expected string
+	}{
+		{0, "00"},
+		{23, "17"},
+		{24, "1818"},
+		{1000, "1903e8"},
+		{1000000000000, "1b000000e8d4a51000"},
+		{uint64(18446744073709551615), "1bffffffffffffffff"},
+		{Number("18446744073709551616"), "c249010000000000000000"},
+		{Number("-18446744073709551616"), "3bffffffffffffffff"},
+		{Number("-18446744073709551617"), "c349010000000000000000"},
+		{-1, "20"},
+		{-1000, "3903e7"},
+		{0.0, "f90000"},
+		{math.Copysign(0, -1), "f98000"},
+		{1.5, "f93e00"},
+		{65504.0, "f97bff"},
+		{100000.0, "fa47c35000"},
+		{3.4028234663852886e+38, "fa7f7fffff"},
+		{1.1, "fb3ff199999999999a"},
+		{5.960464477539063e-8, "f90001"},
+		{0.00006103515625, "f90400"},
+		{-4.0, "f9c400"},
+		{math.Inf(1), "f97c00"},
+		{math.NaN(), "f97e00"},
+		{"IETF", "6449455446"},
+		{"ü", "62c3bc"},
+		{nil, "f6"},
+		{New().Parse(`[1,[2,3]]`)._Array, "8201820203"},
+	}
+
+	for _, c := range cases {
+		data, err := appendCBOR(nil, c.value, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := hex.EncodeToString(data); result != c.expected {
+			t.Errorf("%v: Expected %s, but got %s", c.value, c.expected, result)
+		}
+	}
+}
+
+func TestCBORCanonical(t *testing.T) {
+	mJson := New()
+	mJson.SetOrdered(true)
+	mJson.Parse(`{"aa":1,"b":[true,false],"a":null}`)
+
+	data, _ := mJson.MarshalCBOR()
+	if result := hex.EncodeToString(data); result != "a362616101616282f5f46161f6" {
+		t.Errorf("Expected insertion order, but got %s", result)
+	}
+
+	data, _ = mJson.CanonicalCBOR()
+	if result := hex.EncodeToString(data); result != "a36161f6616282f5f462616101" {
+		t.Errorf("Expected length-first key order, but got %s", result)
+	}
+}
+
+func TestCBORDecodeTypes(t *testing.T) {
+	cases := map[string]string{
+		"1bffffffffffffffff":         `18446744073709551615`,
+		"3bffffffffffffffff":         `-18446744073709551616`,
+		"c249010000000000000000":     `18446744073709551616`,
+		"c349010000000000000000":     `-18446744073709551617`,
+		"c24101":                     `1`,
+		"fa47c35000":                 `100000`,
+		"f93e00":                     `1.5`,
+		"f90001":                     `5.960464477539063e-08`,
+		"4401020304":                 `AQIDBA==`,
+		"5f42010243030405ff":         `AQIDBAU=`,
+		"7f657374726561646d696e67ff": `streaming`,
+		"9fff":                       `[]`,
+		"9f018202039f0405ffff":       `[1,[2,3],[4,5]]`,
+		"bf61610161629f0203ffff":     `{"a":1,"b":[2,3]}`,
+		"a201020304":                 `{"1":2,"3":4}`,
+		"82f7f6":                     `[null,null]`,
+		"c074323031332d30332d32315432303a30343a30305a": `2013-03-21T20:04:00Z`,
+		"c11a514b67b0":         `2013-03-21T20:04:00Z`,
+		"c1fb41d452d9ec200000": `2013-03-21T20:04:00.5Z`,
+		"d9d9f7a1616101":       `{"a":1}`,
+	}
+
+	for input, expected := range cases {
+		data, _ := hex.DecodeString(input)
+		mJson, err := New().ParseCBOR(data)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+
+		if result := mJson.ToString(); result != expected {
+			t.Errorf("%s: Expected %s, but got %s", input, expected, result)
+		}
+	}
+}
+
+func TestCBORNonFinite(t *testing.T) {
+	for _, input := range []string{"f97e00", "fb7ff0000000000000", "82f97c00fbfff0000000000000", "a16161f97e00"} {
+		data, _ := hex.DecodeString(input)
+		if _, err := New().ParseCBOR(data); !errors.Is(err, ErrNonFiniteFloat) {
+			t.Errorf("%s: Expected ErrNonFiniteFloat, but got %v", input, err)
+		}
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteString)
+	defer SetNonFiniteFloatPolicy(NonFiniteDrop)
+
+	data, _ := hex.DecodeString("82f97e00fb7ff0000000000000")
+	mJson, err := New().ParseCBOR(data)
+	if err != nil || mJson.ToString() != `["NaN","Infinity"]` {
+		t.Errorf("Expected the policy strings, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteNull)
+	data, _ = hex.DecodeString("a16161f97e00")
+	mJson, err = New().ParseCBOR(data)
+	if err != nil || mJson.ToString() != `{"a":null}` {
+		t.Errorf("Expected null, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	data, _ = hex.DecodeString("c1f97e00")
+	if _, err := New().ParseCBOR(data); !errors.Is(err, ErrInvalidCBOR) {
+		t.Errorf("Expected a NaN epoch time to stay invalid, but got %v", err)
+	}
+}
+
+func TestCBORErrors(t *testing.T) {
+	inputs := []string{
+		"",
+		"8201",
+		"6461",
+		"1c",
+		"ff",
+		"f0",
+		"f818",
+		"0101",
+		"5f6161ff",
+		"62c328",
+		"c06161",
+		"c1f97e00",
+		"a1818001",
+		"9bffffffffffffffff",
+		"c26161",
+		"c201",
+		"c3a0",
+		"c2",
+	}
+
+	for _, input := range inputs {
+		data, _ := hex.DecodeString(input)
+		if _, err := New().ParseCBOR(data); !errors.Is(err, ErrInvalidCBOR) {
+			t.Errorf("%s: Expected ErrInvalidCBOR, but got %v", input, err)
+		}
+	}
+
+	data, _ := hex.DecodeString("c26461626364")
+	if _, err := New().ParseCBOR(data); err == nil || !strings.Contains(err.Error(), "invalid content for tag 2") {
+		t.Errorf("Expected invalid content for tag 2, but got %v", err)
+	}
+
+	data, _ = hex.DecodeString("d74401020304")
+	if _, err := New().ParseCBOR(data); !errors.Is(err, ErrUnsupportedCBORTag) {
+		t.Errorf("Expected ErrUnsupportedCBORTag, but got %v", err)
+	}
+}
@@ -0,0 +1,255 @@
+package djson
+
+import (
+	"math"
+	"strings"
+)
+
+// Coercion decides how Int, Uint, Float, Bool and String and their Path forms
+// convert the value they find.
+//
+// CoercionDefault keeps the historical rules of each accessor.
+//
+// CoercionStrict accepts exact types only, as GetInt and friends do: Int and
+// Uint want an integer in range, Float any number, Bool a bool and String a
+// string.
+//
+// CoercionLenient also accepts
+//   - for Int and Uint: floats and numeric strings with an integral value in
+//     range, so 3.0 and "3" give 3 but 3.5 fails;
+//   - for Float: numeric strings such as "2.5" or " 1e3 ";
+//   - for Bool: the strings true, yes, 1 and on, or false, no, 0 and off, in
+//     any case, and the numbers 1 and 0;
+//   - for String: numbers and bools, written as ToString writes them.
+//
+// Under strict and lenient rules a value that does not convert, a missing key
+// and null all give the default argument, or the zero value without one.
+// String with no key is ToString in every mode.
+type Coercion int
+
+const (
+	CoercionDefault Coercion = iota
+	CoercionStrict
+	CoercionLenient
+)
+
+// SetCoercion sets the rules the accessors of this document use, including
+// those of its *DO and *DA containers. Objects and arrays parsed or put into
+// it later inherit them.
+
+func (m *JSON) SetCoercion(c Coercion) *JSON {
+	m._Coercion = c
+
+	switch m._Type {
+	case OBJECT:
+		m._Object.SetCoercion(c)
+	case ARRAY:
+		m._Array.SetCoercion(c)
+	}
+
+	return m
+}
+
+// SetCoercion sets the rules of Int, Uint, Float, Bool and String on m and on
+// the objects and arrays in it.
+
+func (m *DO) SetCoercion(c Coercion) *DO {
+	m.coercion = c
+
+	if m.lazy == nil {
+		for _, v := range m.Map {
+			setCoercionOf(v, c)
+		}
+	}
+
+	return m
+}
+
+func (m *DA) SetCoercion(c Coercion) *DA {
+	m.coercion = c
+
+	if m.lazy == nil {
+		for _, v := range m.Element {
+			setCoercionOf(v, c)
+		}
+	}
+
+	return m
+}
+
+func setCoercionOf(v interface{}, c Coercion) {
+	switch t := v.(type) {
+	case *DO:
+		if t != nil {
+			t.SetCoercion(c)
+		}
+	case *DA:
+		if t != nil {
+			t.SetCoercion(c)
+		}
+	}
+}
+
+// WithCoercion returns a view of m that reads with c, for a single call such
+// as m.WithCoercion(CoercionStrict).Int("port"). The view shares m's data.
+
+func (m *JSON) WithCoercion(c Coercion) *JSON {
+	view := *m
+	view._Coercion = c
+	return &view
+}
+
+func (m *JSON) Coercion() Coercion {
+	return m._Coercion
+}
+
+func coerced[T any](m *JSON, key []interface{}, convert func(interface{}, Coercion) (T, bool)) T {
+	var dv T
+
+	if len(key) >= 2 {
+		if v, ok := convert(key[1], CoercionLenient); ok {
+			dv = v
+		}
+	}
+
+	var tokens []interface{}
+	if !IsEmptyArg(key) {
+		tokens = key[:1]
+	}
+
+	return coercedAt(m, tokens, dv, convert)
+}
+
+func coercedPath[T any](m *JSON, path string, dv []T, convert func(interface{}, Coercion) (T, bool)) T {
+	var d T
+	if len(dv) > 0 {
+		d = dv[0]
+	}
+
+	return coercedAt(m, PathTokenizer(path), d, convert)
+}
+
+func coercedAt[T any](m *JSON, tokens []interface{}, dv T, convert func(interface{}, Coercion) (T, bool)) T {
+	v, err := m.lookup(tokens...)
+	if err != nil {
+		return dv
+	}
+
+	if r, ok := convert(v, m._Coercion); ok {
+		return r
+	}
+	return dv
+}
+
+// lenientNumber turns a numeric string into a Number and rejects bools, so
+// that the strict conversions can finish the job.
+
+func lenientNumber(v interface{}) (interface{}, bool) {
+	switch t := v.(type) {
+	case string:
+		s := strings.TrimSpace(t)
+		if !isNumberLexeme(s) {
+			return nil, false
+		}
+		return Number(s), true
+	case bool:
+		return nil, false
+	}
+
+	return v, true
+}
+
+func coerceInt(v interface{}, c Coercion) (int64, bool) {
+	if c != CoercionLenient {
+		return toStrictInt(v)
+	}
+
+	v, ok := lenientNumber(v)
+	if !ok {
+		return 0, false
+	}
+
+	if i, ok := toStrictInt(v); ok {
+		return i, true
+	}
+
+	f, ok := toStrictFloat(v)
+	if !ok || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+func coerceUint(v interface{}, c Coercion) (uint64, bool) {
+	if c != CoercionLenient {
+		return toStrictUint(v)
+	}
+
+	v, ok := lenientNumber(v)
+	if !ok {
+		return 0, false
+	}
+
+	if u, ok := toStrictUint(v); ok {
+		return u, true
+	}
+
+	f, ok := toStrictFloat(v)
+	if !ok || f != math.Trunc(f) || f < 0 || f >= math.MaxUint64 {
+		return 0, false
+	}
+	return uint64(f), true
+}
+
+func coerceFloat(v interface{}, c Coercion) (float64, bool) {
+	if c != CoercionLenient {
+		return toStrictFloat(v)
+	}
+
+	v, ok := lenientNumber(v)
+	if !ok {
+		return 0, false
+	}
+
+	f, ok := toStrictFloat(v)
+	return f, ok && !math.IsInf(f, 0) && !math.IsNaN(f)
+}
+
+func coerceBool(v interface{}, c Coercion) (bool, bool) {
+	if c != CoercionLenient {
+		return toStrictBool(v)
+	}
+
+	switch t := v.(type) {
+	case bool:
+		return t, true
+	case string:
+		switch strings.ToLower(strings.TrimSpace(t)) {
+		case "true", "yes", "1", "on":
+			return true, true
+		case "false", "no", "0", "off":
+			return false, true
+		}
+		return false, false
+	}
+
+	i, ok := coerceInt(v, c)
+	if !ok || (i != 0 && i != 1) {
+		return false, false
+	}
+	return i == 1, true
+}
+
+func coerceString(v interface{}, c Coercion) (string, bool) {
+	if c != CoercionLenient {
+		return toStrictString(v)
+	}
+
+	if v == nil {
+		return "", false
+	}
+	if r, ok := elementToJSON(v); ok && r._Type != OBJECT && r._Type != ARRAY {
+		return r.ToString(), true
+	}
+	return "", false
+}
@@ -0,0 +1,151 @@
+package djson
+
+import "testing"
+
+const coerceDoc = `{"int":3,"whole":3.0,"half":3.5,"numstr":" 42 ","floatstr":"2.5","yes":"Yes","off":"off","one":1,"two":2,"flag":true,"neg":-1,"name":"djson","nil":null}`
+
+func TestCoercionStrict(t *testing.T) {
+	mJson := New().Parse(coerceDoc).SetCoercion(CoercionStrict)
+
+	ints := map[string]int64{"int": 3, "whole": -1, "numstr": -1, "flag": -1, "nil": -1, "missing": -1}
+	for key, expected := range ints {
+		if result := mJson.Int(key, -1); result != expected {
+			t.Errorf("Int(%s): expected %d, but got %d", key, expected, result)
+		}
+	}
+
+	if result := mJson.Float("int"); result != 3 {
+		t.Errorf("Expected 3, but got %v", result)
+	}
+	if result := mJson.Float("floatstr", 1); result != 1 {
+		t.Errorf("Expected the default, but got %v", result)
+	}
+	if result := mJson.Bool("one"); result {
+		t.Errorf("Expected 1 not to be a bool")
+	}
+	if result := mJson.Bool("flag"); !result {
+		t.Errorf("Expected true")
+	}
+	if result := mJson.String("int", "x"); result != "x" {
+		t.Errorf("Expected the default, but got %s", result)
+	}
+	if result := mJson.StringPath(`[name]`); result != "djson" {
+		t.Errorf("Expected djson, but got %s", result)
+	}
+	if result := mJson.Uint("neg", 7); result != 7 {
+		t.Errorf("Expected the default, but got %d", result)
+	}
+}
+
+func TestCoercionLenient(t *testing.T) {
+	mJson := New().Parse(coerceDoc).SetCoercion(CoercionLenient)
+
+	ints := map[string]int64{"int": 3, "whole": 3, "half": -1, "numstr": 42, "floatstr": -1, "flag": -1, "name": -1}
+	for key, expected := range ints {
+		if result := mJson.Int(key, -1); result != expected {
+			t.Errorf("Int(%s): expected %d, but got %d", key, expected, result)
+		}
+		if result := mJson.IntPath(`[`+key+`]`, -1); result != expected {
+			t.Errorf("IntPath(%s): expected %d, but got %d", key, expected, result)
+		}
+	}
+
+	bools := map[string]int{"yes": 1, "off": 0, "one": 1, "two": -1, "flag": 1, "name": -1, "nil": -1}
+	for key, expected := range bools {
+		result := -1
+		if mJson.Bool(key, true) == mJson.Bool(key, false) {
+			result = 0
+			if mJson.Bool(key) {
+				result = 1
+			}
+		}
+		if result != expected {
+			t.Errorf("Bool(%s): expected %d, but got %d", key, expected, result)
+		}
+	}
+
+	if result := mJson.Float("floatstr"); result != 2.5 {
+		t.Errorf("Expected 2.5, but got %v", result)
+	}
+	if result := mJson.FloatPath(`[name]`, 1); result != 1 {
+		t.Errorf("Expected the default, but got %v", result)
+	}
+	if result := mJson.String("half"); result != "3.5" {
+		t.Errorf("Expected 3.5, but got %s", result)
+	}
+	if result := mJson.String("flag"); result != "true" {
+		t.Errorf("Expected true, but got %s", result)
+	}
+	if result := mJson.String("nil", "x"); result != "x" {
+		t.Errorf("Expected the default, but got %s", result)
+	}
+	if result := mJson.Uint("numstr"); result != 42 {
+		t.Errorf("Expected 42, but got %d", result)
+	}
+}
+
+func TestCoercionPerCall(t *testing.T) {
+	mJson := New().Parse(`{"port":"8080","inner":{"port":"9090"}}`)
+
+	if result := mJson.Int("port"); result != 8080 {
+		t.Errorf("Expected the default rules to parse the string, but got %d", result)
+	}
+	if result := mJson.WithCoercion(CoercionStrict).Int("port", -1); result != -1 {
+		t.Errorf("Expected the default, but got %d", result)
+	}
+	if mJson.Coercion() != CoercionDefault {
+		t.Errorf("Expected WithCoercion not to change the document")
+	}
+
+	inner, _ := mJson.SetCoercion(CoercionStrict).Object("inner")
+	if result := inner.Int("port", -1); result != -1 {
+		t.Errorf("Expected the inner object to inherit the rules, but got %d", result)
+	}
+	if result := mJson.Clone().IntPath(`[inner][port]`, -1); result != -1 {
+		t.Errorf("Expected the clone to keep the rules, but got %d", result)
+	}
+}
+
+func TestCoercionContainers(t *testing.T) {
+	mJson := New().Parse(`{"o":{"n":"7","b":"yes"},"a":["7",1.0,"on"]}`).SetCoercion(CoercionStrict)
+
+	obj, _ := mJson._Object.Object("o")
+	if _, ok := obj.Int("n"); ok {
+		t.Errorf("Expected DO.Int to follow strict rules")
+	}
+	if _, ok := obj.Uint("n"); ok {
+		t.Errorf("Expected DO.Uint to follow strict rules")
+	}
+
+	arr, _ := mJson._Object.Array("a")
+	if _, ok := arr.Float(0); ok {
+		t.Errorf("Expected DA.Float to follow strict rules")
+	}
+
+	mJson.SetCoercion(CoercionLenient)
+	if result, ok := obj.Int("n"); !ok || result != 7 {
+		t.Errorf("Expected 7, but got %d", result)
+	}
+	if result, ok := obj.Bool("b"); !ok || !result {
+		t.Errorf("Expected DO.Bool to accept yes")
+	}
+	if result, ok := arr.Bool(2); !ok || !result {
+		t.Errorf("Expected DA.Bool to accept on")
+	}
+	if result, ok := arr.String2(1); !ok || result != "1" {
+		t.Errorf("Expected 1, but got %s", result)
+	}
+
+	mJson.Put("later", Object{"n": "8"})
+	later, _ := mJson._Object.Object("later")
+	if result, ok := later.Int("n"); !ok || result != 8 {
+		t.Errorf("Expected a put object to inherit the rules, but got %d", result)
+	}
+
+	lJson, _ := New().ParseLazy([]byte(`{"o":{"n":"9"}}`))
+	lJson.SetCoercion(CoercionStrict)
+	lazy, _ := lJson._Object.Object("o")
+	if _, ok := lazy.Int("n"); ok {
+		t.Errorf("Expected a lazy object to inherit the rules")
+	}
+}
@@ -0,0 +1,337 @@
+package djson
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMissingColumn = errors.New("djson: missing CSV column")
+	ErrCSVShape      = errors.New("djson: CSV needs an array of objects")
+)
+
+// MissingColumnPolicy decides what happens when a record is shorter than the
+// header (ParseCSV) or a row has no value for a column (ToCSV).
+type MissingColumnPolicy int
+
+const (
+	// MissingEmpty stores an empty string, or writes an empty cell.
+	MissingEmpty MissingColumnPolicy = iota
+	// MissingNull stores null; on export it writes an empty cell.
+	MissingNull
+	// MissingOmit leaves the key out; on export it writes an empty cell.
+	MissingOmit
+	// MissingError fails with ErrMissingColumn.
+	MissingError
+)
+
+type CSVOptions struct {
+	Comma            rune // field delimiter, ',' when zero
+	Comment          rune // lines starting with it are skipped on import
+	LazyQuotes       bool // accept bare quotes inside unquoted fields
+	TrimLeadingSpace bool
+	QuoteAll         bool // quote every field on export
+	UseCRLF          bool // end exported lines with \r\n
+
+	InferTypes bool // turn JSON numbers and true/false into INT, FLOAT and BOOL
+	Nested     bool // turn dotted headers (name.first) into nested objects
+	Ordered    bool // keep the header order in the objects
+	Missing    MissingColumnPolicy
+}
+
+// ToCSV writes an array of objects (or a single object) as CSV with a header
+// row. Nested objects are flattened into dotted column names, and arrays are
+// written as JSON text. Without columns, every leaf that appears in any row
+// becomes a column, in order of first appearance.
+
+func (m *JSON) ToCSV(w io.Writer, columns ...string) error {
+	return m.WriteCSV(w, CSVOptions{}, columns...)
+}
+
+// WriteCSV is ToCSV with explicit options.
+
+func (m *JSON) WriteCSV(w io.Writer, opts CSVOptions, columns ...string) error {
+	var rows []*DO
+
+	switch m._Type {
+	case OBJECT:
+		rows = []*DO{m._Object}
+	case ARRAY:
+		m._Array.load()
+		for idx, each := range m._Array.Element {
+			obj, ok := each.(*DO)
+			if !ok {
+				return fmt.Errorf("%w: element %d is not an object", ErrCSVShape, idx)
+			}
+			rows = append(rows, obj)
+		}
+	default:
+		return ErrCSVShape
+	}
+
+	if len(columns) == 0 {
+		seen := make(map[string]bool)
+		for _, row := range rows {
+			columns = appendCSVColumns(columns, seen, row, "")
+		}
+	}
+
+	cw := newCSVWriter(w, opts)
+	cw.writeRecord(columns)
+
+	record := make([]string, len(columns))
+	for idx, row := range rows {
+		for i, col := range columns {
+			v, ok := lookupDotted(row, col)
+			if !ok && opts.Missing == MissingError {
+				return fmt.Errorf("%w: %q in row %d", ErrMissingColumn, col, idx)
+			}
+			record[i] = csvCell(v)
+		}
+		cw.writeRecord(record)
+	}
+
+	return cw.w.Flush()
+}
+
+func appendCSVColumns(columns []string, seen map[string]bool, obj *DO, prefix string) []string {
+	for _, k := range obj.Keys() {
+		if sub, ok := obj.Map[k].(*DO); ok && sub.Len() > 0 {
+			columns = appendCSVColumns(columns, seen, sub, prefix+k+".")
+			continue
+		}
+
+		if !seen[prefix+k] {
+			seen[prefix+k] = true
+			columns = append(columns, prefix+k)
+		}
+	}
+	return columns
+}
+
+// lookupDotted finds name.first either as a literal key or by walking nested
+// objects, preferring the literal key at every level.
+
+func lookupDotted(obj *DO, key string) (interface{}, bool) {
+	obj.load()
+
+	if v, ok := obj.Map[key]; ok {
+		return v, true
+	}
+
+	for i := strings.IndexByte(key, '.'); i >= 0; {
+		if sub, ok := obj.Map[key[:i]].(*DO); ok {
+			if v, ok := lookupDotted(sub, key[i+1:]); ok {
+				return v, true
+			}
+		}
+
+		next := strings.IndexByte(key[i+1:], '.')
+		if next < 0 {
+			break
+		}
+		i += next + 1
+	}
+
+	return nil, false
+}
+
+func csvCell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	}
+	return encodeToString(v, "")
+}
+
+type csvWriter struct {
+	w    *bufio.Writer
+	opts CSVOptions
+}
+
+func newCSVWriter(w io.Writer, opts CSVOptions) *csvWriter {
+	if opts.Comma == 0 {
+		opts.Comma = ','
+	}
+	return &csvWriter{w: bufio.NewWriter(w), opts: opts}
+}
+
+func (c *csvWriter) writeRecord(record []string) {
+	for i, field := range record {
+		if i > 0 {
+			c.w.WriteRune(c.opts.Comma)
+		}
+
+		if !c.opts.QuoteAll && !c.needsQuotes(field) {
+			c.w.WriteString(field)
+			continue
+		}
+
+		c.w.WriteByte('"')
+		c.w.WriteString(strings.ReplaceAll(field, `"`, `""`))
+		c.w.WriteByte('"')
+	}
+
+	if c.opts.UseCRLF {
+		c.w.WriteString("\r\n")
+	} else {
+		c.w.WriteByte('\n')
+	}
+}
+
+func (c *csvWriter) needsQuotes(field string) bool {
+	if field == "" {
+		return false
+	}
+
+	if field[0] == ' ' || field[0] == '\t' {
+		return true
+	}
+
+	return strings.ContainsRune(field, c.opts.Comma) || strings.ContainsAny(field, "\"\r\n")
+}
+
+// ParseCSV reads a header row followed by records and returns an ARRAY with
+// one OBJECT per record.
+
+func ParseCSV(r io.Reader, opts CSVOptions) (*JSON, error) {
+	cr := csv.NewReader(r)
+	if opts.Comma != 0 {
+		cr.Comma = opts.Comma
+	}
+	cr.Comment = opts.Comment
+	cr.LazyQuotes = opts.LazyQuotes
+	cr.TrimLeadingSpace = opts.TrimLeadingSpace
+	cr.FieldsPerRecord = -1
+
+	arr := NewDA()
+	m := New()
+	m._Array = arr
+	m._Type = ARRAY
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return m, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return m, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := cr.FieldPos(0)
+		if len(record) > len(header) {
+			return nil, &csv.ParseError{StartLine: line, Line: line, Column: 1, Err: csv.ErrFieldCount}
+		}
+
+		obj := NewDO()
+		if opts.Ordered {
+			obj.SetOrdered(true)
+		}
+
+		for i, key := range header {
+			var v interface{}
+
+			if i < len(record) {
+				v = record[i]
+				if opts.InferTypes {
+					v = inferCSVValue(record[i])
+				}
+			} else {
+				switch opts.Missing {
+				case MissingEmpty:
+					v = ""
+				case MissingOmit:
+					continue
+				case MissingError:
+					return nil, &csv.ParseError{StartLine: line, Line: line, Column: i + 1,
+						Err: fmt.Errorf("%w: %q", ErrMissingColumn, key)}
+				}
+			}
+
+			if !opts.Nested {
+				obj.Put(key, v)
+			} else if err := putDotted(obj, key, v); err != nil {
+				return nil, &csv.ParseError{StartLine: line, Line: line, Column: i + 1, Err: err}
+			}
+		}
+
+		arr.PutArray(obj)
+	}
+}
+
+// inferCSVValue types a cell. Integers beyond int64 stay exact as uint64 or
+// Number, and a number too large for float64, such as 1e400, stays a string.
+
+func inferCSVValue(s string) interface{} {
+	switch s {
+	case "true":
+		return true
+	case "false":
+		return false
+	}
+
+	if !isNumberLexeme(s) {
+		return s
+	}
+
+	if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return i
+	}
+
+	if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+		return u
+	}
+
+	if losslessNumber || !strings.ContainsAny(s, ".eE") {
+		return Number(s)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || isNonFinite(f) {
+		return s
+	}
+	return f
+}
+
+func putDotted(obj *DO, key string, v interface{}) error {
+	parts := strings.Split(key, ".")
+
+	for _, part := range parts[:len(parts)-1] {
+		next, exists := obj.Map[part]
+		if !exists {
+			sub := NewDO().SetOrdered(obj.ordered)
+			obj.Put(part, sub)
+			obj = sub
+			continue
+		}
+
+		sub, ok := next.(*DO)
+		if !ok {
+			return fmt.Errorf("djson: column %q conflicts with %q", key, part)
+		}
+		obj = sub
+	}
+
+	last := parts[len(parts)-1]
+	if _, ok := obj.Map[last].(*DO); ok {
+		return fmt.Errorf("djson: column %q conflicts with a nested column", key)
+	}
+
+	obj.Put(last, v)
+	return nil
+}
@@ -0,0 +1,123 @@
+package djson
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestToCSV(t *testing.T) {
+	mJson := New().Parse(`[
+		{"name":{"first":"Ricardo","last":"Longa"},"idade":28,"skills":["Golang","Android"],"note":"says \"hi\", twice"},
+		{"name":{"first":"kim"},"idade":32.5,"active":true,"empty":{}}
+	]`)
+
+	var buf bytes.Buffer
+	if err := mJson.ToCSV(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "idade,name.first,name.last,note,skills,active,empty\n" +
+		"28,Ricardo,Longa,\"says \"\"hi\"\", twice\",\"[\"\"Golang\"\",\"\"Android\"\"]\",,\n" +
+		"32.5,kim,,,,true,{}\n"
+	if result := buf.String(); result != expected {
+		t.Errorf("Expected %q, but got %q", expected, result)
+	}
+
+	buf.Reset()
+	if err := mJson.WriteCSV(&buf, CSVOptions{Comma: ';', QuoteAll: true, UseCRLF: true}, "name.first", "idade"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = "\"name.first\";\"idade\"\r\n\"Ricardo\";\"28\"\r\n\"kim\";\"32.5\"\r\n"
+	if result := buf.String(); result != expected {
+		t.Errorf("Expected %q, but got %q", expected, result)
+	}
+
+	if err := mJson.WriteCSV(&buf, CSVOptions{Missing: MissingError}, "name.last"); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("Expected ErrMissingColumn, but got %v", err)
+	}
+
+	if err := New().Parse(`[1,2]`).ToCSV(&buf); !errors.Is(err, ErrCSVShape) {
+		t.Errorf("Expected ErrCSVShape, but got %v", err)
+	}
+}
+
+func TestParseCSV(t *testing.T) {
+	doc := "name.first,name.last,idade,active,zip\n" +
+		"Ricardo,Longa,28,true,01234\n" +
+		"\"kim, seo\",,32.5,false\n"
+
+	mJson, err := ParseCSV(strings.NewReader(doc), CSVOptions{InferTypes: true, Nested: true, Missing: MissingNull})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"active":true,"idade":28,"name":{"first":"Ricardo","last":"Longa"},"zip":"01234"},{"active":false,"idade":32.5,"name":{"first":"kim, seo","last":""},"zip":null}]`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	mJson, err = ParseCSV(strings.NewReader(doc), CSVOptions{Missing: MissingOmit, Ordered: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `[{"name.first":"Ricardo","name.last":"Longa","idade":"28","active":"true","zip":"01234"},{"name.first":"kim, seo","name.last":"","idade":"32.5","active":"false"}]`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if _, err := ParseCSV(strings.NewReader(doc), CSVOptions{Missing: MissingError}); !errors.Is(err, ErrMissingColumn) {
+		t.Errorf("Expected ErrMissingColumn, but got %v", err)
+	}
+
+	if _, err := ParseCSV(strings.NewReader("a\n1,2\n"), CSVOptions{}); err == nil {
+		t.Errorf("Expected error for extra fields")
+	}
+
+	if _, err := ParseCSV(strings.NewReader("a,a.b\n1,2\n"), CSVOptions{Nested: true}); err == nil {
+		t.Errorf("Expected error for conflicting columns")
+	}
+
+	mJson, _ = ParseCSV(strings.NewReader("# comment\na|b\n1|x\n"), CSVOptions{Comma: '|', Comment: '#', InferTypes: true})
+	if result := mJson.ToString(); result != `[{"a":1,"b":"x"}]` {
+		t.Errorf("Expected [{\"a\":1,\"b\":\"x\"}], but got %s", result)
+	}
+}
+
+func TestParseCSVLargeNumbers(t *testing.T) {
+	doc := "big,huge,neg,inf,small\n" +
+		"18446744073709551615,123456789012345678901234567890,-9223372036854775809,1e400,1e-400\n"
+
+	mJson, err := ParseCSV(strings.NewReader(doc), CSVOptions{InferTypes: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"big":18446744073709551615,"huge":123456789012345678901234567890,"inf":"1e400","neg":-9223372036854775809,"small":0}]`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if v, err := Get[uint64](mJson, `[0][big]`); err != nil || v != 18446744073709551615 {
+		t.Errorf("Expected MaxUint64, but got %d (%v)", v, err)
+	}
+}
+
+func TestCSVRoundTrip(t *testing.T) {
+	mJson := New().Parse(`[{"id":1,"user":{"name":"a","admin":false}},{"id":2,"user":{"name":"b","admin":true}}]`)
+
+	var buf bytes.Buffer
+	mJson.ToCSV(&buf)
+
+	back, err := ParseCSV(&buf, CSVOptions{InferTypes: true, Nested: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := back.ToString(); result != mJson.ToString() {
+		t.Errorf("Expected %s, but got %s", mJson.ToString(), result)
+	}
+}
@@ -0,0 +1,309 @@
+package djson
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Decoder reads a stream of top-level JSON values, either newline-delimited
+// (NDJSON) or simply concatenated, and yields one *JSON per value.
+//
+// When LineDelimited is set every non-blank line is one record, so a broken
+// line never swallows the lines after it. Otherwise records are split by
+// bracket matching, and a value that is never closed consumes the rest of
+// the stream.
+//
+// When SkipInvalid is set, malformed records are counted and skipped instead
+// of being returned as a *RecordError. Ordered makes decoded objects keep
+// their source key order and Lossless keeps numbers as Number.
+// Options limits apply to every record. MaxBytes is checked while a record is
+// read, so an unclosed bracket or string cannot pull the whole stream into
+// memory. An oversized line is a *RecordError like any malformed line; in a
+// concatenated stream the rest cannot be split reliably, so it is fatal.
+//
+// A Decoder must be created with NewDecoder.
+type Decoder struct {
+	LineDelimited bool
+	SkipInvalid   bool
+	Ordered       bool
+	Lossless      bool
+	Options       ParseOptions
+
+	r       *bufio.Reader
+	line    int
+	record  int
+	skipped int
+	err     error
+}
+
+var ErrNoReader = errors.New("djson: Decoder has no reader; use NewDecoder")
+
+// errRecordTooLarge stops reading a record once it exceeds Options.MaxBytes.
+var errRecordTooLarge = errors.New("record too large")
+
+type RecordError struct {
+	Record int
+	Line   int
+	Err    error
+}
+
+func (e *RecordError) Error() string {
+	return fmt.Sprintf("djson: record %d (line %d): %v", e.Record, e.Line, e.Err)
+}
+
+func (e *RecordError) Unwrap() error {
+	return e.Err
+}
+
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r:    bufio.NewReader(r),
+		line: 1,
+	}
+}
+
+// Record returns the 1-based number of the record returned by the last call to Next.
+
+func (d *Decoder) Record() int {
+	return d.record
+}
+
+func (d *Decoder) Skipped() int {
+	return d.skipped
+}
+
+// Next returns the next record, io.EOF at the end of the stream, or a
+// *RecordError for a malformed record. Decoding can continue after a
+// *RecordError; any other error is fatal.
+
+func (d *Decoder) Next() (*JSON, error) {
+	if d.r == nil {
+		return nil, ErrNoReader
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	for {
+		startLine, raw, err := d.readRecord()
+		if err == errRecordTooLarge {
+			d.record++
+			max := d.Options.MaxBytes
+			rerr := &RecordError{
+				Record: d.record,
+				Line:   startLine,
+				Err:    newParser(raw).limitAt(max, ErrMaxBytes, "record exceeds limit of %d bytes", max),
+			}
+
+			if !d.LineDelimited {
+				d.err = rerr
+				return nil, rerr
+			}
+			if d.SkipInvalid {
+				d.skipped++
+				continue
+			}
+			return nil, rerr
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		d.record++
+
+		p := newParser(raw)
+		p.opts = d.Options
+		p.opts.Ordered = p.opts.Ordered || d.Ordered
+		p.opts.Lossless = p.opts.Lossless || d.Lossless || losslessNumber
+
+		v, perr := p.parseDocument()
+		if perr == nil {
+			ret, _ := elementToJSON(v)
+			return ret, nil
+		}
+
+		if d.SkipInvalid {
+			d.skipped++
+			continue
+		}
+
+		return nil, &RecordError{
+			Record: d.record,
+			Line:   startLine,
+			Err:    perr,
+		}
+	}
+}
+
+func (d *Decoder) readRecord() (int, []byte, error) {
+	if d.LineDelimited {
+		for {
+			startLine := d.line
+			raw, err := d.readLine()
+			if err == errRecordTooLarge {
+				return startLine, raw, err
+			}
+
+			if len(bytes.TrimSpace(raw)) > 0 {
+				return startLine, raw, nil
+			}
+
+			if err != nil {
+				return startLine, nil, err
+			}
+		}
+	}
+
+	if err := d.skipSpace(); err != nil {
+		return d.line, nil, err
+	}
+
+	startLine := d.line
+	raw, err := d.scanValue()
+	if err == errRecordTooLarge {
+		return startLine, raw, err
+	}
+	if err != nil && (err != io.EOF || len(raw) == 0) {
+		return startLine, nil, err
+	}
+
+	return startLine, raw, nil
+}
+
+// readLine reads one line, keeping at most MaxBytes+1 bytes of it. The rest
+// of an oversized line is discarded and errRecordTooLarge returned.
+
+func (d *Decoder) readLine() ([]byte, error) {
+	max := d.Options.MaxBytes
+	var raw []byte
+	tooLarge := false
+
+	for {
+		chunk, err := d.r.ReadSlice('\n')
+		if len(chunk) > 0 && chunk[len(chunk)-1] == '\n' {
+			d.line++
+		}
+
+		if !tooLarge {
+			raw = append(raw, chunk...)
+			if max > 0 && len(raw) > max {
+				raw = raw[:max+1]
+				tooLarge = true
+			}
+		}
+
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if tooLarge && (err == nil || err == io.EOF) {
+			return raw, errRecordTooLarge
+		}
+		return raw, err
+	}
+}
+
+func (d *Decoder) tooLarge(raw []byte) bool {
+	return d.Options.MaxBytes > 0 && len(raw) > d.Options.MaxBytes
+}
+
+func (d *Decoder) skipSpace() error {
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return err
+		}
+
+		switch c {
+		case '\n':
+			d.line++
+		case ' ', '\t', '\r':
+		default:
+			return d.r.UnreadByte()
+		}
+	}
+}
+
+// scanValue reads the bytes of one top-level value without decoding it.
+// Containers end at their matching bracket; scalars end at whitespace or
+// at the start of the next container or string.
+
+func (d *Decoder) scanValue() ([]byte, error) {
+	raw := make([]byte, 0, 256)
+
+	first, err := d.r.ReadByte()
+	if err != nil {
+		return raw, err
+	}
+	raw = append(raw, first)
+
+	if first != '{' && first != '[' && first != '"' {
+		for {
+			c, err := d.r.ReadByte()
+			if err != nil {
+				return raw, err
+			}
+
+			switch c {
+			case ' ', '\t', '\r', '\n', '{', '[', '"':
+				return raw, d.r.UnreadByte()
+			}
+
+			raw = append(raw, c)
+			if d.tooLarge(raw) {
+				return raw, errRecordTooLarge
+			}
+		}
+	}
+
+	depth := 0
+	inString := first == '"'
+	escaped := false
+	if !inString {
+		depth = 1
+	}
+
+	for {
+		c, err := d.r.ReadByte()
+		if err != nil {
+			return raw, err
+		}
+		raw = append(raw, c)
+		if d.tooLarge(raw) {
+			return raw, errRecordTooLarge
+		}
+
+		if c == '\n' {
+			d.line++
+		}
+
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+				if depth == 0 {
+					return raw, nil
+				}
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return raw, nil
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package djson
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDecoderConcatenated(t *testing.T) {
+	stream := `{"name":"Ricardo Longa","idade":28}
+{"name":"Hery Victor","idade":32}[1,2,3] "str" 7
+null`
+
+	dec := NewDecoder(strings.NewReader(stream))
+
+	expected := []string{
+		`{"idade":28,"name":"Ricardo Longa"}`,
+		`{"idade":32,"name":"Hery Victor"}`,
+		`[1,2,3]`,
+		`str`,
+		`7`,
+		`null`,
+	}
+
+	for idx := range expected {
+		each, err := dec.Next()
+		if err != nil {
+			t.Fatalf("record %d: %v", idx+1, err)
+		}
+
+		if result := each.ToString(); result != expected[idx] {
+			t.Errorf("Expected %s, but got %s", expected[idx], result)
+		}
+	}
+
+	if _, err := dec.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, but got %v", err)
+	}
+
+	if dec.Record() != len(expected) {
+		t.Errorf("Expected %d records, but got %d", len(expected), dec.Record())
+	}
+}
+
+func TestDecoderRecordError(t *testing.T) {
+	stream := `{"a":1}
+{"a":2,,}
+{"a":3}
+`
+
+	dec := NewDecoder(strings.NewReader(stream))
+
+	if _, err := dec.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := dec.Next()
+	var rerr *RecordError
+	if !errors.As(err, &rerr) {
+		t.Fatalf("Expected *RecordError, but got %v", err)
+	}
+
+	if rerr.Record != 2 || rerr.Line != 2 {
+		t.Errorf("Expected record 2 on line 2, but got record %d on line %d", rerr.Record, rerr.Line)
+	}
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Errorf("Expected wrapped *SyntaxError, but got %v", rerr.Err)
+	}
+
+	each, err := dec.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := each.Int("a"); result != 3 {
+		t.Errorf("Expected 3, but got %d", result)
+	}
+}
+
+func TestDecoderSkipInvalid(t *testing.T) {
+	stream := "{\"a\":1}\n{\"a\":\n\n{\"a\":3}\n\n[4]\n"
+
+	dec := NewDecoder(strings.NewReader(stream))
+	dec.LineDelimited = true
+	dec.SkipInvalid = true
+
+	sum := int64(0)
+	for {
+		each, err := dec.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if each.IsArray() {
+			sum += each.Int(0)
+		} else {
+			sum += each.Int("a")
+		}
+	}
+
+	if sum != 8 {
+		t.Errorf("Expected 8, but got %d", sum)
+	}
+
+	if dec.Skipped() != 1 || dec.Record() != 4 {
+		t.Errorf("Expected 1 skipped of 4 records, but got %d of %d", dec.Skipped(), dec.Record())
+	}
+}
+
+type endlessReader byte
+
+func (r endlessReader) Read(p []byte) (int, error) {
+	for i := range p {
+		p[i] = byte(r)
+	}
+	return len(p), nil
+}
+
+func TestDecoderMaxBytes(t *testing.T) {
+	streams := map[string]io.Reader{
+		"bracket": io.MultiReader(strings.NewReader(`[`), endlessReader('[')),
+		"string":  io.MultiReader(strings.NewReader(`"`), endlessReader('a')),
+		"scalar":  endlessReader('1'),
+	}
+
+	for name, r := range streams {
+		dec := NewDecoder(r)
+		dec.Options.MaxBytes = 1024
+
+		_, err := dec.Next()
+		var rerr *RecordError
+		if !errors.As(err, &rerr) || !errors.Is(err, ErrMaxBytes) {
+			t.Errorf("%s: Expected a *RecordError wrapping ErrMaxBytes, but got %v", name, err)
+		}
+		if _, again := dec.Next(); again != err {
+			t.Errorf("%s: Expected the error to be fatal, but got %v", name, again)
+		}
+	}
+
+	dec := NewDecoder(io.MultiReader(strings.NewReader("[1]\n"), io.LimitReader(endlessReader('x'), 100000), strings.NewReader("\n[2]\n")))
+	dec.LineDelimited = true
+	dec.Options.MaxBytes = 64
+
+	if each, err := dec.Next(); err != nil || each.Int(0) != 1 {
+		t.Fatalf("Expected [1], but got %v", err)
+	}
+	if _, err := dec.Next(); !errors.Is(err, ErrMaxBytes) {
+		t.Errorf("Expected ErrMaxBytes, but got %v", err)
+	}
+	if each, err := dec.Next(); err != nil || each.Int(0) != 2 || dec.Record() != 3 {
+		t.Errorf("Expected [2] as record 3, but got %v (record %d)", err, dec.Record())
+	}
+}
+
+func TestDecoderZeroValue(t *testing.T) {
+	var dec Decoder
+	if _, err := dec.Next(); !errors.Is(err, ErrNoReader) {
+		t.Errorf("Expected ErrNoReader, but got %v", err)
+	}
+}
@@ -1,11 +1,17 @@
 package djson
 
 import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
 	"reflect"
 	"strconv"
 	"strings"
+	"time"
 
 	gov "github.com/asaskevich/govalidator"
+	"github.com/volatiletech/null/v8"
 )
 
 type numbers interface {
@@ -26,14 +32,22 @@ const (
 	BOOL   = 6
 )
 
+var ErrNotNull = errors.New("djson: cannot parse into a non-null JSON")
+
 type JSON struct {
-	_Object *DO
-	_Array  *DA
-	_String string
-	_Int    int64
-	_Float  float64
-	_Bool   bool
-	_Type   int
+	_Object   *DO
+	_Array    *DA
+	_String   string
+	_Int      int64
+	_Unsigned bool // _Int holds the bits of a uint64 above MaxInt64
+	_Float    float64
+	_Bool     bool
+	_Type     int
+	_Ordered  bool
+	_Lossless bool
+	_Number   Number
+	_Source   *formatSource
+	_Coercion Coercion
 }
 
 func New(v ...int) *JSON {
@@ -128,13 +142,37 @@ func NewArray(v ...interface{}) *JSON {
 }
 
 func (m *JSON) SetToObject() *JSON {
-	m._Object = NewDO()
+	m._Object = m.newObject()
 	m._Array = nil
 	m._Type = OBJECT
 
 	return m
 }
 
+// SetOrdered makes the objects of this document keep insertion order,
+// including objects parsed or put into it later.
+
+func (m *JSON) SetOrdered(ordered bool) *JSON {
+	m._Ordered = ordered
+
+	switch m._Type {
+	case OBJECT:
+		m._Object.setOrderedDeep(ordered)
+	case ARRAY:
+		m._Array.setOrderedDeep(ordered)
+	}
+
+	return m
+}
+
+func (m *JSON) newObject() *DO {
+	if m._Ordered {
+		return NewOrderedDO()
+	}
+
+	return NewDO()
+}
+
 func (m *JSON) SetToArray() *JSON {
 	m._Array = NewDA()
 	m._Object = nil
@@ -144,53 +182,132 @@ func (m *JSON) SetToArray() *JSON {
 }
 
 func (m *JSON) Parse(doc string) *JSON {
+	m.ParseE(doc)
+	return m
+}
 
+// ParseE works like Parse but reports why an object or array document was rejected.
+// The returned error is a *SyntaxError carrying the position of the problem.
+
+func (m *JSON) ParseE(doc string) (*JSON, error) {
+	return m.ParseBytes([]byte(doc))
+}
+
+func (m *JSON) ParseBytes(doc []byte) (*JSON, error) {
+	return m.ParseWithOptions(doc, ParseOptions{})
+}
+
+// ParseStrict parses doc with ScalarStrict, so a top-level scalar must be
+// valid JSON: "abc" gives the STRING abc, while TRUE, bare words and empty
+// input are errors.
+
+func (m *JSON) ParseStrict(doc []byte) (*JSON, error) {
+	return m.ParseWithOptions(doc, ParseOptions{Scalars: ScalarStrict})
+}
+
+// ParseWithOptions works like ParseBytes but enforces the limits and the
+// duplicate key policy in opts. A violated limit is reported as a
+// *SyntaxError wrapping ErrMaxBytes, ErrMaxDepth, ErrMaxKeys, ErrMaxArrayLen,
+// ErrMaxStringLen or ErrDuplicateKey, and nothing is assigned to m.
+
+func (m *JSON) ParseWithOptions(doc []byte, opts ParseOptions) (*JSON, error) {
 	if m._Type != NULL {
-		return m
+		return m, ErrNotNull
+	}
+
+	p := newParser(doc)
+	p.opts = opts
+	p.opts.Ordered = opts.Ordered || m._Ordered
+	p.opts.Lossless = opts.Lossless || m._Lossless || losslessNumber
+
+	if opts.MaxBytes > 0 && len(doc) > opts.MaxBytes {
+		return m, p.limitAt(0, ErrMaxBytes, "document of %d bytes exceeds limit of %d", len(doc), opts.MaxBytes)
 	}
 
-	tdoc := strings.TrimSpace(doc)
-	if tdoc == "" {
+	strict := opts.Scalars == ScalarStrict
+
+	// JSON whitespace only, as skipSpace sees it
+	tdoc := bytes.Trim(doc, " \t\n\r")
+	if len(tdoc) == 0 && !strict {
 		m._Type = STRING
 		m._String = ""
-		return m
+		return m, nil
 	}
 
-	var err error
-
-	if tdoc[0] == '{' {
-		m._Object, err = ParseToObject(tdoc)
-		if err == nil {
-			m._Type = OBJECT
+	if !strict && tdoc[0] != '{' && tdoc[0] != '[' && !opts.Relaxed {
+		if p.opts.Lossless && isNumberLexeme(string(tdoc)) {
+			m.setNumber(Number(tdoc))
+			return m, nil
 		}
-	} else if tdoc[0] == '[' {
-		m._Array, err = ParseToArray(tdoc)
-		if err == nil {
-			m._Type = ARRAY
+
+		m.parseScalar(string(tdoc))
+		if m._Type == STRING && opts.MaxStringLen > 0 && len(m._String) > opts.MaxStringLen {
+			m._Type = NULL
+			m._String = ""
+			return m, p.limitAt(0, ErrMaxStringLen, "string exceeds limit of %d bytes", opts.MaxStringLen)
 		}
+		return m, nil
+	}
+
+	v, err := p.parseDocument()
+	if err != nil {
+		return m, err
+	}
+
+	m.setElement(v)
+	if opts.PreserveFormat && (m._Type == OBJECT || m._Type == ARRAY) {
+		m._Source = newFormatSource(doc, opts)
+	}
+	return m, nil
+}
+
+// setElement makes m hold a parsed value while keeping m's own settings.
+
+func (m *JSON) setElement(v interface{}) {
+	if r, ok := elementToJSON(v); ok {
+		m._Object, m._Array, m._Type = r._Object, r._Array, r._Type
+		m._String, m._Bool, m._Int, m._Float, m._Number = r._String, r._Bool, r._Int, r._Float, r._Number
+		m._Unsigned = r._Unsigned
+	}
+
+	if m._Coercion != CoercionDefault {
+		m.SetCoercion(m._Coercion)
+	}
+}
+
+func (m *JSON) ParseReader(r io.Reader) (*JSON, error) {
+	doc, err := io.ReadAll(r)
+	if err != nil {
+		return m, err
+	}
+
+	return m.ParseBytes(doc)
+}
+
+func (m *JSON) parseScalar(tdoc string) {
+	if strings.EqualFold(tdoc, "null") {
+		m._Type = NULL
+	} else if strings.EqualFold(tdoc, "true") || strings.EqualFold(tdoc, "false") {
+		m._Type = BOOL
+		m._Bool, _ = gov.ToBoolean(tdoc)
 	} else {
-		if strings.EqualFold(tdoc, "null") {
-			m._Type = NULL
-		} else if strings.EqualFold(tdoc, "true") || strings.EqualFold(tdoc, "false") {
-			m._Type = BOOL
-			m._Bool, _ = gov.ToBoolean(tdoc)
-		} else {
-			if gov.IsNumeric(tdoc) {
-				if gov.IsInt(tdoc) {
+		if gov.IsNumeric(tdoc) {
+			if gov.IsInt(tdoc) {
+				if u, err := strconv.ParseUint(tdoc, 10, 64); err == nil {
+					m.setUint(u)
+				} else {
 					m._Int, _ = strconv.ParseInt(tdoc, 10, 64)
 					m._Type = INT
-				} else {
-					m._Float, _ = strconv.ParseFloat(tdoc, 64)
-					m._Type = FLOAT
 				}
 			} else {
-				m._String = tdoc
-				m._Type = STRING
+				m._Float, _ = strconv.ParseFloat(tdoc, 64)
+				m._Type = FLOAT
 			}
+		} else {
+			m._String = tdoc
+			m._Type = STRING
 		}
 	}
-
-	return m
 }
 
 func (m *JSON) Put(v ...interface{}) *JSON {
@@ -229,9 +346,34 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 		return m
 	}
 
+	if b, ok := v[0].([]byte); ok {
+		return m.Put(encodeBytes(b))
+	}
+
+	switch t := v[0].(type) {
+	case time.Time:
+		return m.Put(encodeTime(t))
+	case *time.Time:
+		if t == nil {
+			return m.Put(nil)
+		}
+		return m.Put(encodeTime(*t))
+	case null.Time:
+		if !t.Valid {
+			return m.Put(nil)
+		}
+		return m.Put(encodeTime(t.Time))
+	}
+
 	if IsIntType(v[0]) {
 		if m._Type == NULL || m._Type == INT {
-			m._Int, _ = getIntBase(v[0])
+			if u, ok := getUintBase(v[0]); ok && u > math.MaxInt64 {
+				m.setUint(u)
+			} else {
+				m._Int, _ = getIntBase(v[0])
+				m._Unsigned = false
+			}
+			m._Number = ""
 			m._Array = nil
 			m._Object = nil
 			m._Type = INT
@@ -243,7 +385,16 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 
 	if IsFloatType(v[0]) {
 		if m._Type == NULL || m._Type == FLOAT {
+			f, keep := storedFloat(v[0])
+			if !keep {
+				return m
+			}
+			if !IsFloatType(f) {
+				return m.Put(f)
+			}
+
 			m._Float, _ = getFloatBase(v[0])
+			m._Number = ""
 			m._Array = nil
 			m._Object = nil
 			m._Type = FLOAT
@@ -277,30 +428,38 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 		return m
 	}
 
+	if n, ok := toNumber(v[0]); ok {
+		if !isNumberLexeme(string(n)) {
+			return m
+		}
+		if m._Type == NULL || m._Type == INT || m._Type == FLOAT {
+			m.setNumber(n)
+		} else {
+			m.PutArray(n) // best effort
+		}
+		return m
+	}
+
 	switch t := v[0].(type) {
 	case map[string]interface{}:
 		if m._Type == OBJECT {
-			for key := range t {
-				m._Object.Put(key, t[key])
-			}
+			m._Object.Append(t)
 		} else {
-			m._Object = MapToObject(t)
+			m._Object = mapToObject(t, m._Ordered || orderedObject)
 			m._Array = nil
 			m._Type = OBJECT
 		}
 	case Object:
 		if m._Type == OBJECT {
-			for key := range map[string]interface{}(t) {
-				m._Object.Put(key, t[key])
-			}
+			m._Object.Append(t)
 		} else {
-			m._Object = MapToObject(t)
+			m._Object = mapToObject(t, m._Ordered || orderedObject)
 			m._Array = nil
 			m._Type = OBJECT
 		}
 	case *DO:
 		if m._Type == OBJECT {
-			for key := range t.Map {
+			for _, key := range t.Keys() {
 				m._Object.Put(key, t.Map[key])
 			}
 		} else {
@@ -310,7 +469,7 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 		}
 	case DO:
 		if m._Type == OBJECT {
-			for key := range t.Map {
+			for _, key := range t.Keys() {
 				m._Object.Put(key, t.Map[key])
 			}
 		} else {
@@ -336,6 +495,7 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 		}
 	case *DA:
 		if m._Type == ARRAY {
+			t.load()
 			m._Array.Put(t.Element)
 		} else {
 			m._Array = t
@@ -344,6 +504,7 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 		}
 	case DA:
 		if m._Type == ARRAY {
+			t.load()
 			m._Array.Put(t.Element)
 		} else {
 			m._Array = &t
@@ -375,6 +536,30 @@ func (m *JSON) Put(v ...interface{}) *JSON {
 	return m
 }
 
+// PutE works like Put but reports ErrNonFiniteFloat under NonFiniteError
+// and ErrInvalidNumber, leaving m unchanged.
+
+func (m *JSON) PutE(v ...interface{}) error {
+	if err := checkValue(v); err != nil {
+		return err
+	}
+
+	m.Put(v...)
+	return nil
+}
+
+// PutArrayE works like PutArray but reports ErrNonFiniteFloat under
+// NonFiniteError and ErrInvalidNumber, leaving m unchanged.
+
+func (m *JSON) PutArrayE(value ...interface{}) error {
+	if err := checkValue(value); err != nil {
+		return err
+	}
+
+	m.PutArray(value...)
+	return nil
+}
+
 func (m *JSON) PutArray(value ...interface{}) *JSON {
 	if m._Type == NULL {
 		m._Array = NewDA()
@@ -390,7 +575,7 @@ func (m *JSON) PutArray(value ...interface{}) *JSON {
 
 func (m *JSON) PutObject(key string, value interface{}) *JSON {
 	if m._Type == NULL {
-		m._Object = NewDO()
+		m._Object = m.newObject()
 		m._Type = OBJECT
 	}
 
@@ -426,8 +611,17 @@ func (m *JSON) Interface(key ...interface{}) interface{} {
 		case BOOL:
 			return m._Bool
 		case INT:
+			if m._Number != "" {
+				return m._Number
+			}
+			if m._Unsigned {
+				return uint64(m._Int)
+			}
 			return m._Int
 		case FLOAT:
+			if m._Number != "" {
+				return m._Number
+			}
 			return m._Float
 		case OBJECT:
 			return m._Object
@@ -462,7 +656,6 @@ func (m *JSON) Get(key ...interface{}) (*JSON, bool) {
 		return m, true
 	} else {
 
-		r := New()
 		var element interface{}
 		var retOk bool
 
@@ -481,47 +674,58 @@ func (m *JSON) Get(key ...interface{}) (*JSON, bool) {
 			return nil, false
 		}
 
-		eVal := reflect.ValueOf(element)
-
-		switch t := element.(type) {
-		case nil:
-			r._Type = NULL
-		case string:
-			r._String = t
-			r._Type = STRING
-		case bool:
-			r._Bool = t
-			r._Type = BOOL
-		case uint8, uint16, uint32, uint64, uint:
-			intVal := int64(eVal.Uint())
-			r._Int = intVal
-			r._Type = INT
-		case int8, int16, int32, int64, int:
-			intVal := eVal.Int()
-			r._Int = intVal
-			r._Type = INT
-		case float32, float64:
-			floatVal := eVal.Float()
-			r._Float = floatVal
-			r._Type = FLOAT
-		case DA:
-			r._Array = &t
-			r._Type = ARRAY
-		case DO:
-			r._Object = &t
-			r._Type = OBJECT
-		case *DA:
-			r._Array = t
-			r._Type = ARRAY
-		case *DO:
-			r._Object = t
-			r._Type = OBJECT
-		default:
-			return nil, false
+		r, ok := elementToJSON(element)
+		if ok {
+			r._Coercion = m._Coercion
 		}
+		return r, ok
+	}
+}
+
+func elementToJSON(element interface{}) (*JSON, bool) {
+	r := New()
+	eVal := reflect.ValueOf(element)
 
-		return r, true
+	switch t := element.(type) {
+	case nil:
+		r._Type = NULL
+	case string:
+		r._String = t
+		r._Type = STRING
+	case bool:
+		r._Bool = t
+		r._Type = BOOL
+	case uint8, uint16, uint32, uint64, uint:
+		r.setUint(eVal.Uint())
+	case int8, int16, int32, int64, int:
+		intVal := eVal.Int()
+		r._Int = intVal
+		r._Type = INT
+	case float32, float64:
+		floatVal := eVal.Float()
+		r._Float = floatVal
+		r._Type = FLOAT
+	case Number:
+		r.setNumber(t)
+	case DA:
+		r._Array = &t
+		r._Type = ARRAY
+	case DO:
+		r._Object = &t
+		r._Type = OBJECT
+	case *DA:
+		loaded(t)
+		r._Array = t
+		r._Type = ARRAY
+	case *DO:
+		loaded(t)
+		r._Object = t
+		r._Type = OBJECT
+	default:
+		return nil, false
 	}
+
+	return r, true
 }
 
 // The DJSON as return shared Object.
@@ -559,9 +763,10 @@ func (m *JSON) Object(key ...interface{}) (*JSON, bool) {
 
 		if newObject != nil {
 			return &JSON{
-				_Object: newObject,
-				_Array:  nil,
-				_Type:   OBJECT,
+				_Object:   newObject,
+				_Array:    nil,
+				_Type:     OBJECT,
+				_Coercion: m._Coercion,
 			}, true
 		}
 	}
@@ -604,9 +809,10 @@ func (m *JSON) Array(key ...interface{}) (*JSON, bool) {
 
 		if newArray != nil {
 			return &JSON{
-				_Object: nil,
-				_Array:  newArray,
-				_Type:   ARRAY,
+				_Object:   nil,
+				_Array:    newArray,
+				_Type:     ARRAY,
+				_Coercion: m._Coercion,
 			}, true
 		}
 
@@ -616,6 +822,9 @@ func (m *JSON) Array(key ...interface{}) (*JSON, bool) {
 }
 
 func (m *JSON) Int(key ...interface{}) int64 {
+	if m._Coercion != CoercionDefault {
+		return coerced(m, key, coerceInt)
+	}
 
 	if IsEmptyArg(key) {
 
@@ -633,6 +842,9 @@ func (m *JSON) Int(key ...interface{}) int64 {
 			}
 			return 0
 		case INT:
+			if m._Unsigned {
+				return 0
+			}
 			return m._Int
 		case FLOAT:
 			return int64(m._Float)
@@ -672,6 +884,10 @@ func (m *JSON) Int(key ...interface{}) int64 {
 }
 
 func (m *JSON) Bool(key ...interface{}) bool {
+	if m._Coercion != CoercionDefault {
+		return coerced(m, key, coerceBool)
+	}
+
 	if IsEmptyArg(key) {
 
 		switch m._Type {
@@ -693,7 +909,7 @@ func (m *JSON) Bool(key ...interface{}) bool {
 		var dv bool
 
 		if len(key) >= 2 {
-			dv = key[1].(bool)
+			dv, _ = getBoolBase(key[1])
 		}
 
 		switch tkey := key[0].(type) {
@@ -720,6 +936,10 @@ func (m *JSON) Bool(key ...interface{}) bool {
 }
 
 func (m *JSON) Float(key ...interface{}) float64 {
+	if m._Coercion != CoercionDefault {
+		return coerced(m, key, coerceFloat)
+	}
+
 	if IsEmptyArg(key) {
 
 		switch m._Type {
@@ -736,6 +956,9 @@ func (m *JSON) Float(key ...interface{}) float64 {
 			}
 			return 0
 		case INT:
+			if m._Unsigned {
+				return float64(uint64(m._Int))
+			}
 			return float64(m._Int)
 		case FLOAT:
 			return m._Float
@@ -778,11 +1001,15 @@ func (m *JSON) String(key ...interface{}) string {
 
 	if IsEmptyArg(key) {
 		return m.ToString()
+	} else if m._Coercion != CoercionDefault {
+		return coerced(m, key, coerceString)
 	} else {
 		var dv string
 
 		if len(key) >= 2 {
-			dv = key[1].(string)
+			if key[1] != nil {
+				dv, _ = getStringBase(key[1])
+			}
 		}
 
 		switch tkey := key[0].(type) {
@@ -824,12 +1051,21 @@ func (m *JSON) ToString() string {
 	case STRING:
 		return m._String
 	case INT:
+		if m._Number != "" {
+			return string(m._Number)
+		}
+		if m._Unsigned {
+			return strconv.FormatUint(uint64(m._Int), 10)
+		}
 		intStr, ok := getStringBase(m._Int)
 		if !ok {
 			return ""
 		}
 		return intStr
 	case FLOAT:
+		if m._Number != "" {
+			return string(m._Number)
+		}
 		floatStr, ok := getStringBase(m._Float)
 		if !ok {
 			return ""
@@ -909,11 +1145,12 @@ func (m *JSON) Scan() *JSON {
 			ret._Type = INT
 			ret._Int = reflect.ValueOf(t).Int()
 		case uint, uint8, uint16, uint32, uint64:
-			ret._Type = INT
-			ret._Int = int64(reflect.ValueOf(t).Uint())
+			ret.setUint(reflect.ValueOf(t).Uint())
 		case float32, float64:
 			ret._Type = FLOAT
 			ret._Float = reflect.ValueOf(t).Float()
+		case Number:
+			ret.setNumber(t)
 		case *DA:
 			ret._Type = ARRAY
 			ret._Array = t
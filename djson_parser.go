@@ -0,0 +1,793 @@
+package djson
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrMaxBytes     = errors.New("djson: document too large")
+	ErrMaxDepth     = errors.New("djson: nesting too deep")
+	ErrMaxKeys      = errors.New("djson: too many object keys")
+	ErrMaxArrayLen  = errors.New("djson: array too long")
+	ErrMaxStringLen = errors.New("djson: string too long")
+	ErrDuplicateKey = errors.New("djson: duplicate object key")
+)
+
+type DuplicateKeyPolicy int
+
+// ScalarPolicy decides how a document that is not an object or array is read.
+// ScalarLenient keeps the historical behaviour: quotes are kept, case
+// variants of true, false and null are accepted, empty input is an empty
+// STRING and any other text becomes a STRING. ScalarStrict follows RFC 8259:
+// strings are unescaped and anything that is not a JSON value is an error.
+type ScalarPolicy int
+
+const (
+	ScalarLenient ScalarPolicy = iota
+	ScalarStrict
+)
+
+const (
+	DuplicateKeyLastWins DuplicateKeyPolicy = iota
+	DuplicateKeyFirstWins
+	DuplicateKeyError
+)
+
+// ParseOptions bounds what a parse is willing to build. Zero limits mean
+// unlimited, so the zero value parses like ParseBytes.
+// MaxStringLen applies to keys and values, counted in decoded bytes.
+//
+// Relaxed accepts JSON5-style input: // and /* */ comments, trailing commas,
+// single-quoted strings, unquoted identifier keys, hex and +signed numbers,
+// and Infinity/NaN, which are then stored according to NonFinite.
+// A Decoder only splits relaxed records correctly when LineDelimited is set.
+//
+// Lazy validates the whole document but decodes only the top level; nested
+// objects and arrays keep their raw bytes until first accessed. It has no
+// effect together with Relaxed.
+//
+// PreserveFormat keeps the text of an object or array document so that
+// Source returns it with only the bytes touched by UpdatePath, RemovePath,
+// PutObjectToPath, PutArrayToPath and PushBackToPath changed.
+type ParseOptions struct {
+	MaxBytes      int
+	MaxDepth      int
+	MaxKeys       int
+	MaxArrayLen   int
+	MaxStringLen  int
+	DuplicateKeys DuplicateKeyPolicy
+
+	Ordered  bool
+	Lossless bool
+
+	Relaxed   bool
+	NonFinite NonFiniteFloatPolicy
+
+	Lazy bool
+
+	PreserveFormat bool
+
+	Scalars ScalarPolicy
+}
+
+// dropped is returned for a value that NonFiniteDrop leaves out.
+type dropped struct{}
+
+// SyntaxError describes where a document failed to parse.
+// Line and Column are 1-based; Column counts characters, not bytes.
+// Err is set to one of the limit errors (ErrMaxDepth, ErrDuplicateKey, ...)
+// when a ParseOptions limit was hit, so callers can test with errors.Is.
+type SyntaxError struct {
+	Msg     string
+	Offset  int64
+	Line    int
+	Column  int
+	Snippet string
+	Err     error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("djson: %s at line %d, column %d (offset %d) near %q", e.Msg, e.Line, e.Column, e.Offset, e.Snippet)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+const snippetRadius = 16
+
+type parser struct {
+	data  []byte
+	pos   int
+	depth int
+	opts  ParseOptions
+}
+
+func newParser(data []byte) *parser {
+	return &parser{
+		data: data,
+	}
+}
+
+func (p *parser) limitAt(pos int, err error, format string, args ...interface{}) *SyntaxError {
+	e := p.errorAt(pos, format, args...)
+	e.Err = err
+	return e
+}
+
+func (p *parser) errorAt(pos int, format string, args ...interface{}) *SyntaxError {
+	if pos > len(p.data) {
+		pos = len(p.data)
+	}
+
+	line, lineStart := 1, 0
+	for i := 0; i < pos; i++ {
+		if p.data[i] == '\n' {
+			line++
+			lineStart = i + 1
+		}
+	}
+
+	return &SyntaxError{
+		Msg:     fmt.Sprintf(format, args...),
+		Offset:  int64(pos),
+		Line:    line,
+		Column:  utf8.RuneCount(p.data[lineStart:pos]) + 1,
+		Snippet: p.snippet(pos),
+	}
+}
+
+func (p *parser) snippet(pos int) string {
+	from := pos - snippetRadius
+	if from < 0 {
+		from = 0
+	}
+	to := pos + snippetRadius
+	if to > len(p.data) {
+		to = len(p.data)
+	}
+
+	for from > 0 && !utf8.RuneStart(p.data[from]) {
+		from--
+	}
+	for to < len(p.data) && !utf8.RuneStart(p.data[to]) {
+		to++
+	}
+
+	return string(p.data[from:to])
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.data) {
+		switch p.data[p.pos] {
+		case ' ', '\t', '\n', '\r':
+			p.pos++
+		default:
+			if !p.opts.Relaxed || !p.skipRelaxedSpace() {
+				return
+			}
+		}
+	}
+}
+
+// skipRelaxedSpace skips one comment or JSON5-only white space character.
+// An unterminated block comment runs to the end of input.
+
+func (p *parser) skipRelaxedSpace() bool {
+	rest := p.data[p.pos:]
+
+	switch {
+	case rest[0] == '\v' || rest[0] == '\f':
+		p.pos++
+	case bytes.HasPrefix(rest, []byte("//")):
+		if i := bytes.IndexByte(rest, '\n'); i >= 0 {
+			p.pos += i + 1
+		} else {
+			p.pos = len(p.data)
+		}
+	case bytes.HasPrefix(rest, []byte("/*")):
+		if i := bytes.Index(rest[2:], []byte("*/")); i >= 0 {
+			p.pos += i + 4
+		} else {
+			p.pos = len(p.data)
+		}
+	case rest[0] >= utf8.RuneSelf:
+		r, size := utf8.DecodeRune(rest)
+		if r != '\ufeff' && r != '\u2028' && r != '\u2029' && !unicode.Is(unicode.Zs, r) {
+			return false
+		}
+		p.pos += size
+	default:
+		return false
+	}
+
+	return true
+}
+
+func (p *parser) describe(pos int) string {
+	if pos >= len(p.data) {
+		return "unexpected end of input"
+	}
+
+	r, _ := utf8.DecodeRune(p.data[pos:])
+	return fmt.Sprintf("invalid character %q", r)
+}
+
+// parseDocument parses exactly one value followed by optional whitespace.
+
+func (p *parser) parseDocument() (interface{}, error) {
+	if p.opts.MaxBytes > 0 && len(p.data) > p.opts.MaxBytes {
+		return nil, p.limitAt(0, ErrMaxBytes, "document of %d bytes exceeds limit of %d", len(p.data), p.opts.MaxBytes)
+	}
+
+	if p.opts.Lazy && !p.opts.Relaxed {
+		return p.parseLazyDocument()
+	}
+
+	v, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.data) {
+		return nil, p.errorAt(p.pos, "%s after top-level value", p.describe(p.pos))
+	}
+
+	return v, nil
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.opts.MaxDepth > 0 && p.depth > p.opts.MaxDepth {
+		return p.limitAt(p.pos, ErrMaxDepth, "nesting depth exceeds limit of %d", p.opts.MaxDepth)
+	}
+	return nil
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	p.skipSpace()
+
+	if p.pos >= len(p.data) {
+		return nil, p.errorAt(p.pos, "unexpected end of input looking for value")
+	}
+
+	switch c := p.data[p.pos]; {
+	case (c == '{' || c == '[') && p.depth > 0 && p.opts.Lazy && !p.opts.Relaxed:
+		return p.lazyValue()
+	case c == '{':
+		return p.parseObject()
+	case c == '[':
+		return p.parseArray()
+	case c == '"' || (c == '\'' && p.opts.Relaxed):
+		return p.parseString()
+	case c == '-' || (c >= '0' && c <= '9'):
+		return p.parseNumber()
+	case p.opts.Relaxed && (c == '+' || c == 'I' || c == 'N'):
+		return p.parseNumber()
+	case c == 't':
+		return true, p.expectLiteral("true")
+	case c == 'f':
+		return false, p.expectLiteral("false")
+	case c == 'n':
+		return nil, p.expectLiteral("null")
+	}
+
+	return nil, p.errorAt(p.pos, "%s looking for beginning of value", p.describe(p.pos))
+}
+
+func (p *parser) expectLiteral(lit string) error {
+	for i := 0; i < len(lit); i++ {
+		if p.pos+i >= len(p.data) || p.data[p.pos+i] != lit[i] {
+			return p.errorAt(p.pos+i, "%s in literal %s", p.describe(p.pos+i), lit)
+		}
+	}
+
+	p.pos += len(lit)
+	return nil
+}
+
+func (p *parser) parseObject() (*DO, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	obj := NewDO()
+	if p.opts.Ordered {
+		obj.SetOrdered(true)
+	}
+	p.pos++ // '{'
+
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return obj, nil
+	}
+
+	for {
+		p.skipSpace()
+		keyPos := p.pos
+
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+
+		_, dup := obj.Map[key]
+		switch {
+		case dup && p.opts.DuplicateKeys == DuplicateKeyError:
+			return nil, p.limitAt(keyPos, ErrDuplicateKey, "duplicate key %q", key)
+		case !dup && p.opts.MaxKeys > 0 && len(obj.Map) >= p.opts.MaxKeys:
+			return nil, p.limitAt(keyPos, ErrMaxKeys, "object exceeds limit of %d keys", p.opts.MaxKeys)
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return nil, p.errorAt(p.pos, "%s after object key", p.describe(p.pos))
+		}
+		p.pos++
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, drop := v.(dropped); !drop && (!dup || p.opts.DuplicateKeys != DuplicateKeyFirstWins) {
+			obj.Put(key, v)
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorAt(p.pos, "unexpected end of input in object")
+		}
+
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			if p.opts.Relaxed {
+				p.skipSpace()
+				if p.pos < len(p.data) && p.data[p.pos] == '}' {
+					p.pos++
+					return obj, nil
+				}
+			}
+		case '}':
+			p.pos++
+			return obj, nil
+		default:
+			return nil, p.errorAt(p.pos, "%s after object key:value pair", p.describe(p.pos))
+		}
+	}
+}
+
+func (p *parser) parseKey() (string, error) {
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == '"':
+		return p.parseString()
+	case p.opts.Relaxed && p.pos < len(p.data) && p.data[p.pos] == '\'':
+		return p.parseString()
+	case p.opts.Relaxed && p.isIdentifierStart():
+		return p.parseIdentifier(), nil
+	}
+
+	return "", p.errorAt(p.pos, "%s looking for beginning of object key string", p.describe(p.pos))
+}
+
+func (p *parser) parseArray() (*DA, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { p.depth-- }()
+
+	arr := NewDA()
+	p.pos++ // '['
+
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return arr, nil
+	}
+
+	for {
+		if p.opts.MaxArrayLen > 0 && arr.Size() >= p.opts.MaxArrayLen {
+			p.skipSpace()
+			return nil, p.limitAt(p.pos, ErrMaxArrayLen, "array exceeds limit of %d elements", p.opts.MaxArrayLen)
+		}
+
+		v, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, drop := v.(dropped); !drop {
+			arr.PutArray(v)
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return nil, p.errorAt(p.pos, "unexpected end of input in array")
+		}
+
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+			if p.opts.Relaxed {
+				p.skipSpace()
+				if p.pos < len(p.data) && p.data[p.pos] == ']' {
+					p.pos++
+					return arr, nil
+				}
+			}
+		case ']':
+			p.pos++
+			return arr, nil
+		default:
+			return nil, p.errorAt(p.pos, "%s after array element", p.describe(p.pos))
+		}
+	}
+}
+
+// parseNumber follows the RFC 8259 number grammar and converts the lexeme
+// the same way ParseObject converts a json.Number.
+
+func (p *parser) parseNumber() (interface{}, error) {
+	start := p.pos
+
+	if p.data[p.pos] == '-' || (p.opts.Relaxed && p.data[p.pos] == '+') {
+		p.pos++
+	}
+
+	if p.opts.Relaxed && p.pos < len(p.data) {
+		switch {
+		case p.data[p.pos] == 'I' || p.data[p.pos] == 'N':
+			return p.parseNonFinite(start)
+		case p.data[p.pos] == '0' && p.pos+1 < len(p.data) && (p.data[p.pos+1] == 'x' || p.data[p.pos+1] == 'X'):
+			return p.parseHexNumber(start)
+		}
+	}
+
+	if err := p.scanDecimal(); err != nil {
+		return nil, err
+	}
+
+	lexeme := string(p.data[start:p.pos])
+	if lexeme[0] == '+' {
+		lexeme = lexeme[1:]
+	}
+
+	if p.opts.Lossless {
+		return Number(lexeme), nil
+	}
+
+	if i, err := strconv.ParseInt(lexeme, 10, 64); err == nil {
+		return i, nil
+	}
+
+	if u, err := strconv.ParseUint(lexeme, 10, 64); err == nil {
+		return u, nil
+	}
+
+	f, _ := strconv.ParseFloat(lexeme, 64)
+	return f, nil
+}
+
+// scanDecimal moves past the digits, fraction and exponent of a number.
+
+func (p *parser) scanDecimal() error {
+	switch {
+	case p.pos < len(p.data) && p.data[p.pos] == '0':
+		p.pos++
+	case p.pos < len(p.data) && p.data[p.pos] >= '1' && p.data[p.pos] <= '9':
+		p.skipDigits()
+	default:
+		return p.errorAt(p.pos, "%s in numeric literal", p.describe(p.pos))
+	}
+
+	if p.pos < len(p.data) && p.data[p.pos] == '.' {
+		p.pos++
+		if !p.skipDigits() {
+			return p.errorAt(p.pos, "%s after decimal point in numeric literal", p.describe(p.pos))
+		}
+	}
+
+	if p.pos < len(p.data) && (p.data[p.pos] == 'e' || p.data[p.pos] == 'E') {
+		p.pos++
+		if p.pos < len(p.data) && (p.data[p.pos] == '+' || p.data[p.pos] == '-') {
+			p.pos++
+		}
+		if !p.skipDigits() {
+			return p.errorAt(p.pos, "%s in exponent of numeric literal", p.describe(p.pos))
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) parseHexNumber(start int) (interface{}, error) {
+	p.pos += 2 // "0x"
+
+	digits := p.pos
+	for p.pos < len(p.data) && isHexDigit(p.data[p.pos]) {
+		p.pos++
+	}
+	if p.pos == digits {
+		return nil, p.errorAt(p.pos, "%s in hexadecimal numeric literal", p.describe(p.pos))
+	}
+
+	n, _ := new(big.Int).SetString(string(p.data[digits:p.pos]), 16)
+	if p.data[start] == '-' {
+		n.Neg(n)
+	}
+
+	if p.opts.Lossless {
+		return Number(n.String()), nil
+	}
+
+	if n.IsInt64() {
+		return n.Int64(), nil
+	}
+
+	f, _ := new(big.Float).SetInt(n).Float64()
+	return f, nil
+}
+
+func (p *parser) parseNonFinite(start int) (interface{}, error) {
+	var f float64
+
+	switch {
+	case bytes.HasPrefix(p.data[p.pos:], []byte("Infinity")):
+		p.pos += len("Infinity")
+		f = math.Inf(1)
+		if p.data[start] == '-' {
+			f = math.Inf(-1)
+		}
+	case bytes.HasPrefix(p.data[p.pos:], []byte("NaN")):
+		p.pos += len("NaN")
+		f = math.NaN()
+	default:
+		return nil, p.errorAt(p.pos, "%s in numeric literal", p.describe(p.pos))
+	}
+
+	v, keep, err := nonFiniteValue(f, p.opts.NonFinite)
+	if err != nil {
+		return nil, p.limitAt(start, err, "non-finite number %s", p.data[start:p.pos])
+	}
+	if !keep {
+		return dropped{}, nil
+	}
+
+	return v, nil
+}
+
+func (p *parser) isIdentifierStart() bool {
+	if p.pos >= len(p.data) {
+		return false
+	}
+
+	r, _ := utf8.DecodeRune(p.data[p.pos:])
+	return r == '_' || r == '$' || unicode.IsLetter(r)
+}
+
+func (p *parser) parseIdentifier() string {
+	start := p.pos
+
+	for p.pos < len(p.data) {
+		r, size := utf8.DecodeRune(p.data[p.pos:])
+		if r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			break
+		}
+		p.pos += size
+	}
+
+	return string(p.data[start:p.pos])
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func (p *parser) skipDigits() bool {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+		p.pos++
+	}
+	return p.pos > start
+}
+
+func (p *parser) parseString() (string, error) {
+	start := p.pos
+	quote := p.data[p.pos]
+	p.pos++
+
+	// fast path: no escapes and valid UTF-8
+	for i := p.pos; i < len(p.data); i++ {
+		c := p.data[i]
+		if c == quote {
+			if utf8.Valid(p.data[p.pos:i]) {
+				if err := p.checkStringLen(start, i-p.pos); err != nil {
+					return "", err
+				}
+				s := string(p.data[p.pos:i])
+				p.pos = i + 1
+				return s, nil
+			}
+			break
+		}
+		if c == '\\' || c < 0x20 {
+			break
+		}
+	}
+
+	buf := make([]byte, 0, 32)
+
+	for {
+		if p.pos >= len(p.data) {
+			return "", p.errorAt(start, "unterminated string")
+		}
+
+		if err := p.checkStringLen(start, len(buf)); err != nil {
+			return "", err
+		}
+
+		c := p.data[p.pos]
+
+		switch {
+		case c == quote:
+			p.pos++
+			return string(buf), nil
+		case c < 0x20:
+			return "", p.errorAt(p.pos, "invalid character %q in string literal", rune(c))
+		case c == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return "", err
+			}
+			if r >= 0 {
+				buf = utf8.AppendRune(buf, r)
+			}
+		case c < utf8.RuneSelf:
+			buf = append(buf, c)
+			p.pos++
+		default:
+			r, size := utf8.DecodeRune(p.data[p.pos:])
+			buf = utf8.AppendRune(buf, r) // invalid bytes become U+FFFD
+			p.pos += size
+		}
+	}
+}
+
+func (p *parser) checkStringLen(start, n int) error {
+	if p.opts.MaxStringLen > 0 && n > p.opts.MaxStringLen {
+		return p.limitAt(start, ErrMaxStringLen, "string exceeds limit of %d bytes", p.opts.MaxStringLen)
+	}
+	return nil
+}
+
+func (p *parser) parseEscape() (rune, error) {
+	p.pos++ // '\\'
+
+	if p.pos >= len(p.data) {
+		return 0, p.errorAt(p.pos, "unexpected end of input in string escape")
+	}
+
+	c := p.data[p.pos]
+	p.pos++
+
+	switch c {
+	case '"', '\\', '/':
+		return rune(c), nil
+	case 'b':
+		return '\b', nil
+	case 'f':
+		return '\f', nil
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case 'u':
+		r, err := p.parseHex4()
+		if err != nil {
+			return 0, err
+		}
+
+		if utf16.IsSurrogate(r) {
+			if p.pos+1 < len(p.data) && p.data[p.pos] == '\\' && p.data[p.pos+1] == 'u' {
+				save := p.pos
+				p.pos += 2
+				r2, err := p.parseHex4()
+				if err != nil {
+					return 0, err
+				}
+				if dec := utf16.DecodeRune(r, r2); dec != utf8.RuneError {
+					return dec, nil
+				}
+				p.pos = save
+			}
+			return utf8.RuneError, nil
+		}
+
+		return r, nil
+	}
+
+	if p.opts.Relaxed {
+		if r, ok := p.parseRelaxedEscape(c); ok {
+			return r, nil
+		}
+	}
+
+	return 0, p.errorAt(p.pos-1, "invalid character %q in string escape code", rune(c))
+}
+
+// parseRelaxedEscape handles the JSON5 escapes that follow a backslash.
+// A line continuation yields -1, meaning no character.
+
+func (p *parser) parseRelaxedEscape(c byte) (rune, bool) {
+	switch c {
+	case '\'':
+		return '\'', true
+	case 'v':
+		return '\v', true
+	case '0':
+		if p.pos < len(p.data) && p.data[p.pos] >= '0' && p.data[p.pos] <= '9' {
+			return 0, false
+		}
+		return 0, true
+	case 'x':
+		if p.pos+2 > len(p.data) || !isHexDigit(p.data[p.pos]) || !isHexDigit(p.data[p.pos+1]) {
+			return 0, false
+		}
+		n, _ := strconv.ParseUint(string(p.data[p.pos:p.pos+2]), 16, 8)
+		p.pos += 2
+		return rune(n), true
+	case '\n':
+		return -1, true
+	case '\r':
+		if p.pos < len(p.data) && p.data[p.pos] == '\n' {
+			p.pos++
+		}
+		return -1, true
+	}
+
+	if bytes.HasPrefix(p.data[p.pos-1:], []byte("\u2028")) || bytes.HasPrefix(p.data[p.pos-1:], []byte("\u2029")) {
+		p.pos += 2
+		return -1, true
+	}
+
+	return 0, false
+}
+
+func (p *parser) parseHex4() (rune, error) {
+	if p.pos+4 > len(p.data) {
+		return 0, p.errorAt(len(p.data), "unexpected end of input in \\u escape")
+	}
+
+	var r rune
+	for i := 0; i < 4; i++ {
+		c := p.data[p.pos+i]
+		switch {
+		case c >= '0' && c <= '9':
+			c -= '0'
+		case c >= 'a' && c <= 'f':
+			c = c - 'a' + 10
+		case c >= 'A' && c <= 'F':
+			c = c - 'A' + 10
+		default:
+			return 0, p.errorAt(p.pos+i, "invalid character %q in \\u hexadecimal character escape", rune(c))
+		}
+		r = r*16 + rune(c)
+	}
+
+	p.pos += 4
+	return r, nil
+}
@@ -0,0 +1,327 @@
+package djson
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseESyntaxError(t *testing.T) {
+	jsonDoc := `{
+	"name": "Ricardo Longa",
+	"idade": 28,,
+	"skills": ["Golang", "Android"]
+}`
+
+	mJson, err := New().ParseE(jsonDoc)
+	if err == nil {
+		t.Fatalf("Expected error, but got %s", mJson.ToString())
+	}
+
+	if !mJson.IsNull() {
+		t.Errorf("Expected null, but got %s", mJson.Type())
+	}
+
+	var serr *SyntaxError
+	if !errors.As(err, &serr) {
+		t.Fatalf("Expected *SyntaxError, but got %T", err)
+	}
+
+	if serr.Line != 3 || serr.Column != 14 || serr.Offset != 41 {
+		t.Errorf("Expected line 3, column 14, offset 41, but got %d, %d, %d", serr.Line, serr.Column, serr.Offset)
+	}
+
+	if !strings.Contains(serr.Snippet, "28,,") {
+		t.Errorf("Expected snippet around '28,,', but got %q", serr.Snippet)
+	}
+}
+
+func TestParseEErrors(t *testing.T) {
+	docs := []string{
+		`{"a":1`,
+		`{"a" 1}`,
+		`{a:1}`,
+		`[1,2,]`,
+		`[01]`,
+		`["abc]`,
+		`["\x"]`,
+		`{"a":tru}`,
+		`{"a":1} {"b":2}`,
+	}
+
+	for _, doc := range docs {
+		if _, err := New().ParseE(doc); err == nil {
+			t.Errorf("Expected error for %s", doc)
+		}
+	}
+}
+
+func TestParseEValues(t *testing.T) {
+	jsonDoc := `{"s":"a\"bé😀","i":-12,"f":1.5e2,"b":false,"n":null,"a":[[1],{"k":"v"}]}`
+
+	mJson, err := New().ParseE(jsonDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := mJson.String("s"); result != "a\"bé😀" {
+		t.Errorf("Expected %q, but got %q", "a\"bé😀", result)
+	}
+
+	if result := mJson.Int("i"); result != -12 {
+		t.Errorf("Expected -12, but got %d", result)
+	}
+
+	if result := mJson.Float("f"); result != 150 {
+		t.Errorf("Expected 150, but got %v", result)
+	}
+
+	expected, _ := ParseToObject(jsonDoc)
+	if result := mJson.ToString(); result != expected.ToString() {
+		t.Errorf("Expected %s, but got %s", expected.ToString(), result)
+	}
+}
+
+func TestParseReader(t *testing.T) {
+	mJson, err := New().ParseReader(strings.NewReader(`[1, 2, 3]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := mJson.ToString(); result != `[1,2,3]` {
+		t.Errorf("Expected [1,2,3], but got %s", result)
+	}
+
+	if _, err := mJson.ParseBytes([]byte(`[4]`)); !errors.Is(err, ErrNotNull) {
+		t.Errorf("Expected ErrNotNull, but got %v", err)
+	}
+}
+
+func TestParseWithOptionsLimits(t *testing.T) {
+	opts := ParseOptions{
+		MaxBytes:     64,
+		MaxDepth:     3,
+		MaxKeys:      2,
+		MaxArrayLen:  3,
+		MaxStringLen: 5,
+	}
+
+	cases := map[string]error{
+		`{"a":[1,2,3],"b":{"c":"hello"}}`:         nil,
+		`{"a":"` + strings.Repeat("x", 70) + `"}`: ErrMaxBytes,
+		`[[[[1]]]]`:                         ErrMaxDepth,
+		`{"a":1,"b":2,"c":3}`:               ErrMaxKeys,
+		`[1,2,3,4]`:                         ErrMaxArrayLen,
+		`{"a":"hello!"}`:                    ErrMaxStringLen,
+		`{"abcdef":1}`:                      ErrMaxStringLen,
+		`["abcde\n"]`:                       ErrMaxStringLen,
+		`{"a":1,"a":2}`:                     nil,
+		`"` + strings.Repeat("y", 10) + `"`: ErrMaxStringLen,
+		strings.Repeat("z", 6):              ErrMaxStringLen,
+	}
+
+	for doc, expected := range cases {
+		mJson, err := New().ParseWithOptions([]byte(doc), opts)
+		if !errors.Is(err, expected) {
+			t.Errorf("%s: Expected %v, but got %v", doc, expected, err)
+		}
+
+		if expected != nil && !mJson.IsNull() {
+			t.Errorf("%s: Expected null, but got %s", doc, mJson.Type())
+		}
+	}
+}
+
+func TestParseWithOptionsDuplicateKeys(t *testing.T) {
+	doc := []byte(`{"a":1,"b":2,"a":3}`)
+
+	mJson, _ := New().ParseWithOptions(doc, ParseOptions{})
+	if result := mJson.Int("a"); result != 3 {
+		t.Errorf("Expected 3, but got %d", result)
+	}
+
+	mJson, _ = New().ParseWithOptions(doc, ParseOptions{DuplicateKeys: DuplicateKeyFirstWins})
+	if result := mJson.Int("a"); result != 1 {
+		t.Errorf("Expected 1, but got %d", result)
+	}
+
+	_, err := New().ParseWithOptions(doc, ParseOptions{DuplicateKeys: DuplicateKeyError})
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || !errors.Is(err, ErrDuplicateKey) {
+		t.Fatalf("Expected ErrDuplicateKey, but got %v", err)
+	}
+
+	if serr.Offset != 13 {
+		t.Errorf("Expected offset 13, but got %d", serr.Offset)
+	}
+
+	_, err = New().ParseWithOptions([]byte(`{"a":1,"b":2,"a":3}`), ParseOptions{MaxKeys: 2})
+	if err != nil {
+		t.Errorf("Expected duplicates not to count against MaxKeys, but got %v", err)
+	}
+}
+
+func TestParseRelaxed(t *testing.T) {
+	jsonDoc := `
+// service config
+{
+	name: 'api-server', /* quoted with 'single' quotes */
+	"port": 0x1F90,
+	$weight: +1.5,
+	tags: ['a', "b\'s", 'c\x41\
+d',],
+	limits: {max: Infinity, min: -Infinity, avg: NaN,},
+}
+`
+
+	mJson, err := New().ParseWithOptions([]byte(jsonDoc), ParseOptions{Relaxed: true, NonFinite: NonFiniteString})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"$weight":1.5,"limits":{"avg":"NaN","max":"Infinity","min":"-Infinity"},"name":"api-server","port":8080,"tags":["a","b's","cAd"]}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	mJson, err = New().ParseWithOptions([]byte(jsonDoc), ParseOptions{Relaxed: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	limits, _ := mJson.Object("limits")
+	if result := limits.ToString(); result != `{}` {
+		t.Errorf("Expected {}, but got %s", result)
+	}
+
+	mJson, _ = New().ParseWithOptions([]byte(jsonDoc), ParseOptions{Relaxed: true, NonFinite: NonFiniteNull})
+	limits, _ = mJson.Object("limits")
+	if !limits.HasKey("max") || !limits.IsNull("max") {
+		t.Errorf("Expected null, but got %s", limits.ToString())
+	}
+
+	_, err = New().ParseWithOptions([]byte(jsonDoc), ParseOptions{Relaxed: true, NonFinite: NonFiniteError})
+	if !errors.Is(err, ErrNonFiniteFloat) {
+		t.Errorf("Expected ErrNonFiniteFloat, but got %v", err)
+	}
+
+	if _, err := New().ParseE(`{a: 1,}`); err == nil {
+		t.Errorf("Expected strict parse to fail")
+	}
+}
+
+func TestParseRelaxedScalarsAndErrors(t *testing.T) {
+	mJson, err := New().ParseWithOptions([]byte("/* c */ -0x10 // hex"), ParseOptions{Relaxed: true})
+	if err != nil || mJson.Int() != -16 {
+		t.Errorf("Expected -16, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	docs := []string{
+		`{a: 1 /* unterminated`,
+		`{a b: 1}`,
+		`[1,,2]`,
+		`{,}`,
+		`['\0', '\01']`,
+		`[Inf]`,
+		`[0x]`,
+	}
+
+	for _, doc := range docs {
+		if _, err := New().ParseWithOptions([]byte(doc), ParseOptions{Relaxed: true}); err == nil {
+			t.Errorf("%s: Expected error", doc)
+		}
+	}
+}
+
+func TestParseTrailingText(t *testing.T) {
+	for _, doc := range []string{`{"a":1} trailing`, `{"a":1}{}`, `[1] 2`, `{"a":1`} {
+		if mJson := New().Parse(doc); mJson._Type != NULL {
+			t.Errorf("%q: Expected Parse to reject it, but got %s", doc, mJson.ToString())
+		}
+		if _, err := ParseToObject(doc); err == nil {
+			t.Errorf("%q: Expected ParseToObject to reject it", doc)
+		}
+		if _, err := ParseToArray(doc); err == nil {
+			t.Errorf("%q: Expected ParseToArray to reject it", doc)
+		}
+	}
+
+	for _, doc := range []string{" {\"a\":[1,2]} \n", "[{\"a\":1}]"} {
+		expected := New().Parse(doc).ToString()
+
+		var result string
+		if obj, err := ParseToObject(doc); err == nil {
+			result = New().Put(obj).ToString()
+		} else if arr, err := ParseToArray(doc); err == nil {
+			result = New().Put(arr).ToString()
+		}
+		if result != expected {
+			t.Errorf("%q: Expected %s from both entry points, but got %s", doc, expected, result)
+		}
+	}
+}
+
+func TestParseBytesWhitespace(t *testing.T) {
+	if mJson, err := New().ParseBytes([]byte(" \t\r\n{\"a\":1}\n")); err != nil || mJson.ToString() != `{"a":1}` {
+		t.Errorf("Expected the object, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	for _, doc := range []string{"\v{\"a\":1}", "\f[1]", "\u00a0{}"} {
+		mJson, err := New().ParseBytes([]byte(doc))
+		if err != nil || mJson._Type != STRING || mJson.ToString() != doc {
+			t.Errorf("%q: Expected a STRING scalar, but got %s (type %d, %v)", doc, mJson.ToString(), mJson._Type, err)
+		}
+
+		if _, err := New().ParseStrict([]byte(doc)); err == nil {
+			t.Errorf("%q: Expected the strict parser to reject it", doc)
+		}
+	}
+}
+
+func TestParseStrictScalars(t *testing.T) {
+	tests := []struct {
+		doc      string
+		typ      int
+		expected string
+	}{
+		{`"abc"`, STRING, `abc`},
+		{` "tab\tand é" `, STRING, "tab\tand é"},
+		{`42`, INT, `42`},
+		{`-1.5e3`, FLOAT, `-1500`},
+		{`true`, BOOL, `true`},
+		{`null`, NULL, `null`},
+		{`{"a":1}`, OBJECT, `{"a":1}`},
+	}
+
+	for _, test := range tests {
+		mJson, err := New().ParseStrict([]byte(test.doc))
+		if err != nil {
+			t.Errorf("%s: %v", test.doc, err)
+			continue
+		}
+		if mJson._Type != test.typ || mJson.ToString() != test.expected {
+			t.Errorf("%s: Expected %s, but got %s (type %d)", test.doc, test.expected, mJson.ToString(), mJson._Type)
+		}
+	}
+
+	docs := []string{``, `  `, `abc`, `TRUE`, `Null`, `"abc`, `'abc'`, `01`, `1 2`, `"a" "b"`}
+	for _, doc := range docs {
+		mJson, err := New().ParseStrict([]byte(doc))
+		if err == nil {
+			t.Errorf("%q: Expected error, but got %s", doc, mJson.ToString())
+			continue
+		}
+		if mJson._Type != NULL {
+			t.Errorf("%q: Expected nothing to be assigned", doc)
+		}
+	}
+
+	if mJson, err := New().ParseWithOptions([]byte(`"abc"`), ParseOptions{Scalars: ScalarLenient}); err != nil || mJson.ToString() != `"abc"` {
+		t.Errorf("Expected the lenient policy to keep the quotes, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	_, err := New().ParseWithOptions([]byte(`"abcdef"`), ParseOptions{Scalars: ScalarStrict, MaxStringLen: 3})
+	if !errors.Is(err, ErrMaxStringLen) {
+		t.Errorf("Expected ErrMaxStringLen, but got %v", err)
+	}
+}
@@ -51,6 +51,10 @@ func (m *JSON) ArrayPath(path string) (*JSON, bool) {
 }
 
 func (m *JSON) FloatPath(path string, dv ...float64) float64 {
+	if m._Coercion != CoercionDefault {
+		return coercedPath(m, path, dv, coerceFloat)
+	}
+
 	var ret float64
 	var kok bool
 
@@ -75,6 +79,10 @@ func (m *JSON) FloatPath(path string, dv ...float64) float64 {
 }
 
 func (m *JSON) IntPath(path string, dv ...int64) int64 {
+	if m._Coercion != CoercionDefault {
+		return coercedPath(m, path, dv, coerceInt)
+	}
+
 	var ret int64
 	var kok bool
 
@@ -99,6 +107,10 @@ func (m *JSON) IntPath(path string, dv ...int64) int64 {
 }
 
 func (m *JSON) BoolPath(path string, dv ...bool) bool {
+	if m._Coercion != CoercionDefault {
+		return coercedPath(m, path, dv, coerceBool)
+	}
+
 	var ret bool
 	var kok bool
 
@@ -123,6 +135,10 @@ func (m *JSON) BoolPath(path string, dv ...bool) bool {
 }
 
 func (m *JSON) StringPath(path string) string {
+	if m._Coercion != CoercionDefault {
+		return coercedPath(m, path, nil, coerceString)
+	}
+
 	var ret string
 
 	_ = m.DoPathFunc(path, nil,
@@ -188,7 +204,7 @@ func (m *JSON) SortPath(path string, isAsc bool, k ...string) bool {
 }
 
 func (m *JSON) RemovePath(path string) bool {
-	return m.DoPathFunc(path, nil,
+	ok := m.DoPathFunc(path, nil,
 		func(da *DA, idx int, v interface{}) {
 			da.Remove(idx)
 		},
@@ -196,10 +212,15 @@ func (m *JSON) RemovePath(path string) bool {
 			do.Remove(key)
 		},
 	)
+
+	if ok {
+		m.editSource(sourceRemove, PathTokenizer(path)...)
+	}
+	return ok
 }
 
 func (m *JSON) PutObjectToPath(path string, okey string, oval interface{}) bool {
-	return m.DoPathFunc(path, oval,
+	ok := m.DoPathFunc(path, oval,
 		func(da *DA, idx int, v interface{}) {
 			da.Insert(idx, Object{okey: v})
 		},
@@ -207,12 +228,17 @@ func (m *JSON) PutObjectToPath(path string, okey string, oval interface{}) bool
 			do.Put(key, Object{okey: v})
 		},
 	)
+
+	if ok {
+		m.editSource(sourceInsert, PathTokenizer(path)...)
+	}
+	return ok
 }
 
 // Replace or insert values as array
 
 func (m *JSON) PutArrayToPath(path string, val ...interface{}) bool {
-	return m.DoPathFunc(path, val,
+	ok := m.DoPathFunc(path, val,
 		func(da *DA, idx int, v interface{}) {
 			da.Insert(idx, v)
 		},
@@ -220,13 +246,18 @@ func (m *JSON) PutArrayToPath(path string, val ...interface{}) bool {
 			do.Put(key, v)
 		},
 	)
+
+	if ok {
+		m.editSource(sourceInsert, PathTokenizer(path)...)
+	}
+	return ok
 }
 
 // Pushback a value to array if possible.
 // The path must indicate array.
 
 func (m *JSON) PushBackToPath(path string, val interface{}) bool {
-	return m.DoPathFunc(path, val,
+	ok := m.DoPathFunc(path, val,
 		func(da *DA, idx int, v interface{}) {
 			if dda, ok := da.Array(idx); ok {
 				dda.PushBack(v)
@@ -238,12 +269,17 @@ func (m *JSON) PushBackToPath(path string, val interface{}) bool {
 			}
 		},
 	)
+
+	if ok {
+		m.pushBackSource(path)
+	}
+	return ok
 }
 
 // Replace or insert a value
 
 func (m *JSON) UpdatePath(path string, val interface{}) bool {
-	return m.DoPathFunc(path, val,
+	ok := m.DoPathFunc(path, val,
 		func(da *DA, idx int, v interface{}) {
 			da.ReplaceAt(idx, v)
 		},
@@ -251,6 +287,22 @@ func (m *JSON) UpdatePath(path string, val interface{}) bool {
 			do.Put(key, v)
 		},
 	)
+
+	if ok {
+		m.editSource(sourceSet, PathTokenizer(path)...)
+	}
+	return ok
+}
+
+// UpdatePathE works like UpdatePath but reports ErrNonFiniteFloat under
+// NonFiniteError and ErrInvalidNumber, leaving m unchanged.
+
+func (m *JSON) UpdatePathE(path string, val interface{}) (bool, error) {
+	if err := checkValue(val); err != nil {
+		return false, err
+	}
+
+	return m.UpdatePath(path, val), nil
 }
 
 func (m *JSON) doPathFuncCore(
@@ -270,6 +322,7 @@ func (m *JSON) doPathFuncCore(
 			if jsonMode != OBJECT || dObject == nil {
 				return false
 			}
+			dObject.load()
 
 			if idx == tokenLen-1 {
 				objectTaskFunc(dObject, tkey, val)
@@ -296,6 +349,7 @@ func (m *JSON) doPathFuncCore(
 			if jsonMode != ARRAY || dArray == nil {
 				return false
 			}
+			dArray.load()
 
 			for dArray.Size() < tkey {
 				dArray.PushBack(0)
@@ -339,16 +393,12 @@ func (m *JSON) KeysPath(path string) ([]string, bool) {
 	pok := m.DoPathFunc(path, nil,
 		func(da *DA, idx int, v interface{}) {
 			if ddo, ok := da.Object(idx); ok {
-				for k := range ddo.Map {
-					rk = append(rk, k)
-				}
+				rk = ddo.Keys()
 			}
 		},
 		func(do *DO, key string, v interface{}) {
 			if ddo, ok := do.Object(key); ok {
-				for k := range ddo.Map {
-					rk = append(rk, k)
-				}
+				rk = ddo.Keys()
 			}
 		},
 	)
@@ -3,6 +3,7 @@ package djson
 import (
 	"reflect"
 	"strings"
+	"time"
 
 	"github.com/volatiletech/null/v8"
 )
@@ -65,6 +66,20 @@ func (m *JSON) toFieldsValue(val reflect.Value, tags ...string) {
 			case "null.String":
 				eval.FieldByName("String").SetString(m.String(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
+			case "time.Time":
+				if tm, ok := m.Time(eachTag); ok {
+					eval.Set(reflect.ValueOf(tm))
+				}
+			case "null.Time":
+				if tm, ok := m.Time(eachTag); ok {
+					eval.FieldByName("Time").Set(reflect.ValueOf(tm))
+					eval.FieldByName("Valid").SetBool(true)
+				}
+			case "null.Bytes":
+				if b, ok := m.Bytes(eachTag); ok {
+					eval.FieldByName("Bytes").SetBytes(b)
+					eval.FieldByName("Valid").SetBool(true)
+				}
 			case "null.Bool":
 				eval.FieldByName("Bool").SetBool(m.Bool(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
@@ -90,19 +105,19 @@ func (m *JSON) toFieldsValue(val reflect.Value, tags ...string) {
 				eval.FieldByName("Int64").SetInt(m.Int(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			case "null.Uint":
-				eval.FieldByName("Uint").SetUint(uint64(m.Int(eachTag)))
+				eval.FieldByName("Uint").SetUint(m.Uint(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			case "null.Uint8":
-				eval.FieldByName("Uint8").SetUint(uint64(m.Int(eachTag)))
+				eval.FieldByName("Uint8").SetUint(m.Uint(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			case "null.Uint16":
-				eval.FieldByName("Uint16").SetUint(uint64(m.Int(eachTag)))
+				eval.FieldByName("Uint16").SetUint(m.Uint(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			case "null.Uint32":
-				eval.FieldByName("Uint32").SetUint(uint64(m.Int(eachTag)))
+				eval.FieldByName("Uint32").SetUint(m.Uint(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			case "null.Uint64":
-				eval.FieldByName("Uint64").SetUint(uint64(m.Int(eachTag)))
+				eval.FieldByName("Uint64").SetUint(m.Uint(eachTag))
 				eval.FieldByName("Valid").SetBool(true)
 			default:
 
@@ -118,13 +133,21 @@ func (m *JSON) toFieldsValue(val reflect.Value, tags ...string) {
 			case "int", "int8", "int16", "int32", "int64":
 				eval.SetInt(m.Int(eachTag))
 			case "uint", "uint8", "uint16", "uint32", "uint64":
-				eval.SetUint(uint64(m.Int(eachTag)))
+				eval.SetUint(m.Uint(eachTag))
 			case "float32", "float64":
 				eval.SetFloat(m.Float(eachTag))
 			case "string":
 				eval.SetString(m.String(eachTag))
 			case "bool":
 				eval.SetBool(m.Bool(eachTag))
+			case "[]uint8":
+				if b, ok := m.Bytes(eachTag); ok {
+					eval.SetBytes(b)
+				}
+			case "*time.Time":
+				if tm, ok := m.Time(eachTag); ok {
+					eval.Set(reflect.ValueOf(&tm))
+				}
 			}
 		}
 	}
@@ -161,7 +184,13 @@ func (m *JSON) fromFieldsValue(val reflect.Value, tags ...string) {
 				m.PutArray(eachVal.String())
 			case reflect.Float32, reflect.Float64:
 				m.PutArray(eachVal.Float())
-			case reflect.Array, reflect.Slice:
+			case reflect.Slice:
+				if eachType.Elem().Kind() == reflect.Uint8 {
+					m.PutArray(eachVal.Bytes())
+					break
+				}
+				fallthrough
+			case reflect.Array:
 				sJson := New()
 				sJson.SetToArray()
 				sJson.fromFieldsValue(eachVal, downDepthWW(tags)...)
@@ -174,6 +203,16 @@ func (m *JSON) fromFieldsValue(val reflect.Value, tags ...string) {
 					} else {
 						m.PutArray("")
 					}
+				case "time.Time":
+					m.PutArray(eachVal.Interface())
+				case "null.Time":
+					m.PutArray(eachVal.Interface())
+				case "null.Bytes":
+					if eachVal.FieldByName("Valid").Bool() {
+						m.PutArray(eachVal.FieldByName("Bytes").Bytes())
+					} else {
+						m.PutArray("")
+					}
 				case "null.Bool":
 					if eachVal.FieldByName("Valid").Bool() {
 						m.PutArray(eachVal.FieldByName("Bool").Bool())
@@ -304,6 +343,20 @@ func (m *JSON) fromFieldsValue(val reflect.Value, tags ...string) {
 					} else if !omitEmpty {
 						m.Put(tagName, "")
 					}
+				case "time.Time", "*time.Time":
+					m.Put(tagName, eachVal.Interface())
+				case "null.Time":
+					if eachVal.FieldByName("Valid").Bool() {
+						m.Put(tagName, eachVal.FieldByName("Time").Interface())
+					} else if !omitEmpty {
+						m.Put(tagName, nil)
+					}
+				case "null.Bytes":
+					if eachVal.FieldByName("Valid").Bool() {
+						m.Put(tagName, eachVal.FieldByName("Bytes").Bytes())
+					} else if !omitEmpty {
+						m.Put(tagName, "")
+					}
 				case "null.Bool":
 					if eachVal.FieldByName("Valid").Bool() {
 						m.Put(tagName, eachVal.FieldByName("Bool").Bool())
@@ -388,6 +441,12 @@ func (m *JSON) fromFieldsValue(val reflect.Value, tags ...string) {
 					sJson.fromFieldsValue(eachVal, downDepthWW(tags)...)
 					m.Put(tagName, sJson)
 				}
+			} else if eachKind == reflect.Slice && eachVal.Type().Elem().Kind() == reflect.Uint8 {
+
+				if !eachVal.IsNil() || !omitEmpty {
+					m.Put(tagName, eachVal.Bytes())
+				}
+
 			} else if eachKind == reflect.Array || eachKind == reflect.Slice {
 
 				sJson := New()
@@ -456,6 +515,16 @@ func (m *JSON) fromFieldsValue(val reflect.Value, tags ...string) {
 				m.Put(eachKey, t)
 			case nil:
 				m.Put(eachKey, t)
+			case time.Time:
+				m.Put(eachKey, t)
+			case *time.Time:
+				m.Put(eachKey, t)
+			case null.Time:
+				m.Put(eachKey, t)
+			case []byte:
+				m.Put(eachKey, t)
+			case null.Bytes:
+				m.Put(eachKey, t)
 			case null.String:
 				m.Put(eachKey, t)
 			case null.Bool:
@@ -567,9 +636,15 @@ func (m *JSON) Equal(t *JSON) bool {
 		return true
 	case BOOL:
 		return m._Bool == t._Bool
-	case INT:
-		return m._Int == t._Int
-	case FLOAT:
+	case INT, FLOAT:
+		if m._Number != "" || t._Number != "" {
+			mn, _ := getNumberStringBase(m.Interface())
+			tn, _ := getNumberStringBase(t.Interface())
+			return equalNumber(Number(mn), Number(tn))
+		}
+		if m._Type == INT {
+			return m._Int == t._Int && m._Unsigned == t._Unsigned
+		}
 		return m._Float == t._Float
 	case STRING:
 		return m._String == t._String
@@ -584,6 +659,9 @@ func (m *JSON) Equal(t *JSON) bool {
 
 func (m *JSON) Clone() *JSON {
 	t := New(m._Type)
+	t._Ordered = m._Ordered
+	t._Lossless = m._Lossless
+	t._Coercion = m._Coercion
 
 	switch m._Type {
 	case NULL:
@@ -591,8 +669,11 @@ func (m *JSON) Clone() *JSON {
 		t._Bool = m._Bool
 	case INT:
 		t._Int = m._Int
+		t._Unsigned = m._Unsigned
+		t._Number = m._Number
 	case FLOAT:
 		t._Float = m._Float
+		t._Number = m._Number
 	case STRING:
 		t._String = m._String
 	case OBJECT:
@@ -622,11 +703,7 @@ func (m *JSON) GetKeys(k ...interface{}) []string {
 			return rk
 		}
 
-		for k := range m._Object.Map {
-			rk = append(rk, k)
-		}
-
-		return rk
+		return m._Object.Keys()
 	}
 
 	if t, ok := m.Object(k[0]); ok {
@@ -0,0 +1,549 @@
+package djson
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+)
+
+var ErrEncoderState = errors.New("djson: encoder call out of sequence")
+
+type jsonWriter interface {
+	io.Writer
+	io.ByteWriter
+	io.StringWriter
+}
+
+// valueEncoder writes DO/DA trees with the same bytes json.Marshal (or
+// json.MarshalIndent) produces for ObjectToMap/ArrayToSlice, without building
+// the intermediate copies. Ordered objects keep their insertion order.
+type valueEncoder struct {
+	w       jsonWriter
+	scratch []byte
+	indent  string
+	depth   int
+	format  *FormatOptions
+}
+
+func newValueEncoder(w jsonWriter) *valueEncoder {
+	return &valueEncoder{
+		w:       w,
+		scratch: make([]byte, 0, 64),
+	}
+}
+
+func (e *valueEncoder) encode(v interface{}) error {
+	switch t := v.(type) {
+	case nil:
+		_, err := e.w.WriteString("null")
+		return err
+	case string:
+		return e.writeScratch(e.appendString(e.scratch[:0], t))
+	case bool:
+		return e.writeScratch(strconv.AppendBool(e.scratch[:0], t))
+	case int:
+		return e.writeScratch(strconv.AppendInt(e.scratch[:0], int64(t), 10))
+	case int8:
+		return e.writeScratch(strconv.AppendInt(e.scratch[:0], int64(t), 10))
+	case int16:
+		return e.writeScratch(strconv.AppendInt(e.scratch[:0], int64(t), 10))
+	case int32:
+		return e.writeScratch(strconv.AppendInt(e.scratch[:0], int64(t), 10))
+	case int64:
+		return e.writeScratch(strconv.AppendInt(e.scratch[:0], t, 10))
+	case uint:
+		return e.writeScratch(strconv.AppendUint(e.scratch[:0], uint64(t), 10))
+	case uint8:
+		return e.writeScratch(strconv.AppendUint(e.scratch[:0], uint64(t), 10))
+	case uint16:
+		return e.writeScratch(strconv.AppendUint(e.scratch[:0], uint64(t), 10))
+	case uint32:
+		return e.writeScratch(strconv.AppendUint(e.scratch[:0], uint64(t), 10))
+	case uint64:
+		return e.writeScratch(strconv.AppendUint(e.scratch[:0], t, 10))
+	case float32:
+		if isNonFinite(float64(t)) {
+			return ErrNonFiniteFloat
+		}
+		return e.writeScratch(e.appendFloat(e.scratch[:0], float64(t), 32))
+	case float64:
+		if isNonFinite(t) {
+			return ErrNonFiniteFloat
+		}
+		return e.writeScratch(e.appendFloat(e.scratch[:0], t, 64))
+	case Number:
+		_, err := e.w.WriteString(string(t))
+		return err
+	case *DO:
+		return e.encodeObject(t)
+	case DO:
+		return e.encodeObject(&t)
+	case *DA:
+		return e.encodeArray(t)
+	case DA:
+		return e.encodeArray(&t)
+	case *JSON:
+		if t == nil {
+			_, err := e.w.WriteString("null")
+			return err
+		}
+		return e.encode(t.Interface())
+	}
+
+	jsonByte, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	_, err = e.w.Write(jsonByte)
+	return err
+}
+
+func (e *valueEncoder) encodeObject(obj *DO) error {
+	if obj != nil && obj.lazy != nil {
+		if e.indent == "" && e.format == nil {
+			return e.writeScratch(appendCompact(e.scratch[:0], obj.lazy.data))
+		}
+		obj.load()
+	}
+
+	if obj == nil || len(obj.Map) == 0 {
+		_, err := e.w.WriteString("{}")
+		return err
+	}
+
+	var keys []string
+	if obj.ordered && (e.format == nil || !e.format.SortKeys) {
+		keys = obj.orderedKeys()
+	} else {
+		keys = obj.sortedKeys()
+	}
+
+	if err := e.w.WriteByte('{'); err != nil {
+		return err
+	}
+
+	e.depth++
+	for idx, k := range keys {
+		if err := e.writeSeparator(idx); err != nil {
+			return err
+		}
+
+		if err := e.writeKey(k); err != nil {
+			return err
+		}
+
+		if err := e.encode(obj.Map[k]); err != nil {
+			return err
+		}
+	}
+	e.depth--
+
+	if err := e.writeNewline(); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte('}')
+}
+
+func (e *valueEncoder) encodeArray(arr *DA) error {
+	if arr != nil && arr.lazy != nil {
+		if e.indent == "" && e.format == nil {
+			return e.writeScratch(appendCompact(e.scratch[:0], arr.lazy.data))
+		}
+		arr.load()
+	}
+
+	if arr == nil || len(arr.Element) == 0 {
+		_, err := e.w.WriteString("[]")
+		return err
+	}
+
+	if e.format != nil && e.format.CompactArrays && e.indent != "" && isPrimitiveArray(arr) {
+		return e.encodeInlineArray(arr)
+	}
+
+	if err := e.w.WriteByte('['); err != nil {
+		return err
+	}
+
+	e.depth++
+	for idx := range arr.Element {
+		if err := e.writeSeparator(idx); err != nil {
+			return err
+		}
+
+		if err := e.encode(arr.Element[idx]); err != nil {
+			return err
+		}
+	}
+	e.depth--
+
+	if err := e.writeNewline(); err != nil {
+		return err
+	}
+
+	return e.w.WriteByte(']')
+}
+
+func (e *valueEncoder) writeSeparator(idx int) error {
+	if idx > 0 {
+		if err := e.w.WriteByte(','); err != nil {
+			return err
+		}
+	}
+
+	return e.writeNewline()
+}
+
+func (e *valueEncoder) writeNewline() error {
+	if e.indent == "" {
+		return nil
+	}
+
+	if err := e.w.WriteByte('\n'); err != nil {
+		return err
+	}
+
+	for i := 0; i < e.depth; i++ {
+		if _, err := e.w.WriteString(e.indent); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (e *valueEncoder) writeKey(key string) error {
+	buf := append(e.appendString(e.scratch[:0], key), ':')
+	if e.indent != "" {
+		buf = append(buf, ' ')
+	}
+
+	return e.writeScratch(buf)
+}
+
+func (e *valueEncoder) writeScratch(buf []byte) error {
+	e.scratch = buf
+	_, err := e.w.Write(buf)
+	return err
+}
+
+func appendFloat(buf []byte, f float64, bitSize int) []byte {
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bitSize == 32 {
+			if f32 := float32(abs); f32 < 1e-6 || f32 >= 1e21 {
+				format = 'e'
+			}
+		} else if abs < 1e-6 || abs >= 1e21 {
+			format = 'e'
+		}
+	}
+
+	return strconv.AppendFloat(buf, f, format, -1, bitSize)
+}
+
+const hexDigits = "0123456789abcdef"
+
+// appendQuoted escapes like json.Marshal: HTML characters, control
+// characters, U+2028/U+2029 and invalid UTF-8 bytes are written as \u escapes.
+
+func appendQuoted(buf []byte, s string) []byte {
+	return appendQuotedWith(buf, s, true, false)
+}
+
+func appendQuotedWith(buf []byte, s string, escapeHTML, ascii bool) []byte {
+	buf = append(buf, '"')
+
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && (!escapeHTML || c != '<' && c != '>' && c != '&') {
+				i++
+				continue
+			}
+
+			buf = append(buf, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				buf = append(buf, '\\', c)
+			case '\n':
+				buf = append(buf, '\\', 'n')
+			case '\r':
+				buf = append(buf, '\\', 'r')
+			case '\t':
+				buf = append(buf, '\\', 't')
+			default:
+				buf = append(buf, '\\', 'u', '0', '0', hexDigits[c>>4], hexDigits[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, `\ufffd`...)
+			i++
+			start = i
+			continue
+		}
+
+		if r == '\u2028' || r == '\u2029' {
+			buf = append(buf, s[start:i]...)
+			buf = append(buf, '\\', 'u', '2', '0', '2', hexDigits[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+
+		if ascii {
+			buf = append(buf, s[start:i]...)
+			buf = appendUnicodeEscape(buf, r)
+			i += size
+			start = i
+			continue
+		}
+
+		i += size
+	}
+
+	buf = append(buf, s[start:]...)
+	return append(buf, '"')
+}
+
+func encodeToString(v interface{}, indent string) string {
+	var sb strings.Builder
+
+	enc := newValueEncoder(&sb)
+	enc.indent = indent
+
+	if err := enc.encode(v); err != nil {
+		return ""
+	}
+
+	return sb.String()
+}
+
+type countWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+func writeTo(w io.Writer, v interface{}) (int64, error) {
+	cw := &countWriter{w: w}
+	bw := bufio.NewWriter(cw)
+
+	var err error
+	if s, ok := v.(string); ok {
+		_, err = bw.WriteString(s)
+	} else {
+		err = newValueEncoder(bw).encode(v)
+	}
+
+	if err == nil {
+		err = bw.Flush()
+	}
+
+	return cw.n, err
+}
+
+// WriteTo streams the same bytes ToString returns. A NaN or ±Inf float,
+// which only gets into the tree through Map or Element directly, fails with
+// ErrNonFiniteFloat.
+
+func (m *JSON) WriteTo(w io.Writer) (int64, error) {
+	switch m._Type {
+	case OBJECT:
+		return writeTo(w, m._Object)
+	case ARRAY:
+		return writeTo(w, m._Array)
+	}
+
+	return writeTo(w, m.ToString())
+}
+
+func (m *DO) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, m)
+}
+
+func (m *DA) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, m)
+}
+
+type encoderFrame struct {
+	isObject bool
+	count    int
+	hasKey   bool
+}
+
+// Encoder writes a document incrementally, so large arrays and objects can be
+// streamed without being materialised as DO/DA first.
+// Consecutive top-level values are separated by a newline.
+// Call Flush when done.
+type Encoder struct {
+	w     *bufio.Writer
+	enc   *valueEncoder
+	stack []encoderFrame
+	count int
+	err   error
+}
+
+func NewEncoder(w io.Writer) *Encoder {
+	bw := bufio.NewWriter(w)
+	return &Encoder{
+		w:   bw,
+		enc: newValueEncoder(bw),
+	}
+}
+
+func (e *Encoder) BeginObject() error {
+	if err := e.beforeValue(); err != nil {
+		return err
+	}
+
+	e.stack = append(e.stack, encoderFrame{isObject: true})
+	return e.fail(e.w.WriteByte('{'))
+}
+
+func (e *Encoder) EndObject() error {
+	return e.end(true, '}')
+}
+
+func (e *Encoder) BeginArray() error {
+	if err := e.beforeValue(); err != nil {
+		return err
+	}
+
+	e.stack = append(e.stack, encoderFrame{isObject: false})
+	return e.fail(e.w.WriteByte('['))
+}
+
+func (e *Encoder) EndArray() error {
+	return e.end(false, ']')
+}
+
+func (e *Encoder) WriteKey(key string) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if len(e.stack) == 0 {
+		return ErrEncoderState
+	}
+
+	top := &e.stack[len(e.stack)-1]
+	if !top.isObject || top.hasKey {
+		return ErrEncoderState
+	}
+
+	if top.count > 0 {
+		if err := e.fail(e.w.WriteByte(',')); err != nil {
+			return err
+		}
+	}
+
+	top.hasKey = true
+	top.count++
+
+	return e.fail(e.enc.writeKey(key))
+}
+
+// WriteValue writes a complete value. Scalars of *JSON are written as JSON,
+// so a STRING is quoted here even though ToString returns it bare, and a nil
+// *JSON is written as null. NaN and ±Inf fail with ErrNonFiniteFloat.
+
+func (e *Encoder) WriteValue(v interface{}) error {
+	if err := e.beforeValue(); err != nil {
+		return err
+	}
+
+	if t, ok := v.(*JSON); ok && t != nil {
+		v = t.Interface()
+	}
+
+	return e.fail(e.enc.encode(v))
+}
+
+func (e *Encoder) Flush() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	return e.fail(e.w.Flush())
+}
+
+func (e *Encoder) beforeValue() error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if len(e.stack) == 0 {
+		if e.count > 0 {
+			if err := e.fail(e.w.WriteByte('\n')); err != nil {
+				return err
+			}
+		}
+		e.count++
+		return nil
+	}
+
+	top := &e.stack[len(e.stack)-1]
+	if top.isObject {
+		if !top.hasKey {
+			return ErrEncoderState
+		}
+		top.hasKey = false
+		return nil
+	}
+
+	if top.count > 0 {
+		if err := e.fail(e.w.WriteByte(',')); err != nil {
+			return err
+		}
+	}
+	top.count++
+
+	return nil
+}
+
+func (e *Encoder) end(isObject bool, closer byte) error {
+	if e.err != nil {
+		return e.err
+	}
+
+	if len(e.stack) == 0 {
+		return ErrEncoderState
+	}
+
+	top := e.stack[len(e.stack)-1]
+	if top.isObject != isObject || top.hasKey {
+		return ErrEncoderState
+	}
+
+	e.stack = e.stack[:len(e.stack)-1]
+	return e.fail(e.w.WriteByte(closer))
+}
+
+func (e *Encoder) fail(err error) error {
+	if err != nil && e.err == nil {
+		e.err = err
+	}
+	return err
+}
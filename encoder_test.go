@@ -0,0 +1,134 @@
+package djson
+
+import (
+	"bytes"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestWriteToMatchesToString(t *testing.T) {
+	mJson := New().Put(Object{
+		"name":    "Ricardo <Longa> & \"co\"\n\t\x01 ",
+		"invalid": "a\xffb",
+		"idade":   28,
+		"uint":    uint64(18446744073709551615),
+		"float":   1.5,
+		"tiny":    0.0000001,
+		"huge":    1e21,
+		"f32":     float32(0.1),
+		"bool":    true,
+		"nil":     nil,
+		"empty":   Object{},
+		"list":    Array{},
+		"skills": Array{
+			"Golang", 1, 2.25, Object{"한글": "값"}, Array{Array{}},
+		},
+	})
+
+	var buf bytes.Buffer
+	n, err := mJson.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := mJson.ToString()
+	if result := buf.String(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if n != int64(len(expected)) {
+		t.Errorf("Expected %d bytes, but got %d", len(expected), n)
+	}
+
+	for _, each := range []*JSON{NewString("abc"), NewInt(7), NewFloat(0.5), NewBool(true), New()} {
+		buf.Reset()
+		each.WriteTo(&buf)
+		if buf.String() != each.ToString() {
+			t.Errorf("Expected %s, but got %s", each.ToString(), buf.String())
+		}
+	}
+}
+
+func TestEncoderStream(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	enc.BeginObject()
+	enc.WriteKey("items")
+	enc.BeginArray()
+	for i := 0; i < 3; i++ {
+		enc.WriteValue(Object{"id": i})
+	}
+	enc.WriteValue(NewString("str"))
+	enc.EndArray()
+	enc.WriteKey("total")
+	enc.WriteValue(3)
+	if err := enc.EndObject(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := enc.Flush(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"items":[{"id":0},{"id":1},{"id":2},"str"],"total":3}`
+	if result := buf.String(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestEncoderState(t *testing.T) {
+	enc := NewEncoder(&strings.Builder{})
+
+	if err := enc.WriteKey("a"); !errors.Is(err, ErrEncoderState) {
+		t.Errorf("Expected ErrEncoderState, but got %v", err)
+	}
+
+	enc.BeginObject()
+	if err := enc.WriteValue(1); !errors.Is(err, ErrEncoderState) {
+		t.Errorf("Expected ErrEncoderState, but got %v", err)
+	}
+
+	if err := enc.EndArray(); !errors.Is(err, ErrEncoderState) {
+		t.Errorf("Expected ErrEncoderState, but got %v", err)
+	}
+}
+
+func TestEncoderInvalidValues(t *testing.T) {
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+
+	var nilJSON *JSON
+	enc.BeginArray()
+	if err := enc.WriteValue(nilJSON); err != nil {
+		t.Fatal(err)
+	}
+	enc.EndArray()
+	enc.Flush()
+
+	if result := buf.String(); result != "[null]" {
+		t.Errorf("Expected [null], but got %s", result)
+	}
+
+	mJson := New().Put(Object{"a": 1})
+	mJson._Object.Map["f"] = math.Inf(1)
+
+	buf.Reset()
+	if _, err := mJson.WriteTo(&buf); !errors.Is(err, ErrNonFiniteFloat) {
+		t.Errorf("Expected ErrNonFiniteFloat, but got %v", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("Expected nothing to be written, but got %s", buf.String())
+	}
+
+	buf.Reset()
+	enc = NewEncoder(&buf)
+	if err := enc.WriteValue(mJson); !errors.Is(err, ErrNonFiniteFloat) {
+		t.Errorf("Expected ErrNonFiniteFloat, but got %v", err)
+	}
+	if err := enc.Flush(); !errors.Is(err, ErrNonFiniteFloat) || buf.Len() != 0 {
+		t.Errorf("Expected the error to stick, but got %v and %s", err, buf.String())
+	}
+}
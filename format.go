@@ -0,0 +1,131 @@
+package djson
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// FormatOptions controls Format. The zero value produces the same text as
+// ToString.
+type FormatOptions struct {
+	Indent            string // one level of indentation; empty writes a single line
+	SortKeys          bool   // sort keys of insertion-ordered objects too
+	DisableHTMLEscape bool   // write <, > and & as they are
+	ASCII             bool   // escape every non-ASCII character as \uXXXX
+	CompactArrays     bool   // keep arrays without objects or arrays on one line
+	NoExponent        bool   // never write floats in exponent notation
+	FloatPrecision    int    // fixed digits after the decimal point when > 0
+}
+
+// Format serializes the document with the given options. Unlike ToString,
+// a STRING document is written as a quoted JSON string.
+
+func (m *JSON) Format(opts FormatOptions) string {
+	return formatToString(m.Interface(), opts)
+}
+
+func (m *DO) Format(opts FormatOptions) string {
+	return formatToString(m, opts)
+}
+
+func (m *DA) Format(opts FormatOptions) string {
+	return formatToString(m, opts)
+}
+
+// ToStringPretty returns OBJECT and ARRAY documents with the same
+// indentation as DO.ToStringPretty; other types return ToString.
+
+func (m *JSON) ToStringPretty() string {
+	switch m._Type {
+	case OBJECT:
+		return m._Object.ToStringPretty()
+	case ARRAY:
+		return m._Array.ToStringPretty()
+	}
+
+	return m.ToString()
+}
+
+func formatToString(v interface{}, opts FormatOptions) string {
+	var sb strings.Builder
+
+	enc := newValueEncoder(&sb)
+	enc.indent = opts.Indent
+	enc.format = &opts
+
+	if err := enc.encode(v); err != nil {
+		return ""
+	}
+
+	return sb.String()
+}
+
+func (e *valueEncoder) appendString(buf []byte, s string) []byte {
+	if e.format == nil {
+		return appendQuoted(buf, s)
+	}
+	return appendQuotedWith(buf, s, !e.format.DisableHTMLEscape, e.format.ASCII)
+}
+
+func (e *valueEncoder) appendFloat(buf []byte, f float64, bitSize int) []byte {
+	switch {
+	case e.format == nil:
+	case e.format.FloatPrecision > 0:
+		return strconv.AppendFloat(buf, f, 'f', e.format.FloatPrecision, bitSize)
+	case e.format.NoExponent:
+		return strconv.AppendFloat(buf, f, 'f', -1, bitSize)
+	}
+
+	return appendFloat(buf, f, bitSize)
+}
+
+func isPrimitiveArray(arr *DA) bool {
+	arr.load()
+
+	for _, each := range arr.Element {
+		switch t := each.(type) {
+		case *DO:
+			if t.Len() > 0 {
+				return false
+			}
+		case *DA:
+			if t.Size() > 0 {
+				return false
+			}
+		case DO, DA:
+			return false
+		}
+	}
+	return true
+}
+
+func (e *valueEncoder) encodeInlineArray(arr *DA) error {
+	if err := e.w.WriteByte('['); err != nil {
+		return err
+	}
+
+	for idx := range arr.Element {
+		if idx > 0 {
+			if _, err := e.w.WriteString(", "); err != nil {
+				return err
+			}
+		}
+
+		if err := e.encode(arr.Element[idx]); err != nil {
+			return err
+		}
+	}
+
+	return e.w.WriteByte(']')
+}
+
+func appendUnicodeEscape(buf []byte, r rune) []byte {
+	if r > 0xFFFF {
+		r1, r2 := utf16.EncodeRune(r)
+		buf = appendUnicodeEscape(buf, r1)
+		return appendUnicodeEscape(buf, r2)
+	}
+
+	return append(buf, '\\', 'u', hexDigits[r>>12&0xF], hexDigits[r>>8&0xF], hexDigits[r>>4&0xF], hexDigits[r&0xF])
+}
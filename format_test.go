@@ -0,0 +1,54 @@
+package djson
+
+import (
+	"testing"
+)
+
+func TestFormat(t *testing.T) {
+	mJson := New()
+	mJson.SetOrdered(true)
+	mJson.Parse(`{"name":"<한글> & 😀","ratio":0.0000001,"tags":[1,2,"x"],"nested":[{"b":true,"a":1.5}],"empty":[]}`)
+
+	if result := mJson.Format(FormatOptions{}); result != mJson.ToString() {
+		t.Errorf("Expected %s, but got %s", mJson.ToString(), result)
+	}
+
+	expected := `{
+  "name": "<\ud55c\uae00> & \ud83d\ude00",
+  "ratio": 0.0000001,
+  "tags": [1, 2, "x"],
+  "nested": [
+    {
+      "b": true,
+      "a": 1.5
+    }
+  ],
+  "empty": []
+}`
+	result := mJson.Format(FormatOptions{Indent: "  ", DisableHTMLEscape: true, ASCII: true, CompactArrays: true, NoExponent: true})
+	if result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	expected = `{"empty":[],"name":"\u003c한글\u003e \u0026 😀","nested":[{"a":1.50,"b":true}],"ratio":0.00,"tags":[1,2,"x"]}`
+	if result := mJson.Format(FormatOptions{SortKeys: true, FloatPrecision: 2}); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result := NewString("a\"b").Format(FormatOptions{}); result != `"a\"b"` {
+		t.Errorf("Expected \"a\\\"b\", but got %s", result)
+	}
+}
+
+func TestToStringPretty(t *testing.T) {
+	mJson := New().Parse(`{"a":[1]}`)
+
+	expected := "{\n   \"a\": [\n      1\n   ]\n}"
+	if result := mJson.ToStringPretty(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result := New().Parse(`12`).ToStringPretty(); result != "12" {
+		t.Errorf("Expected 12, but got %s", result)
+	}
+}
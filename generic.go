@@ -0,0 +1,365 @@
+package djson
+
+import (
+	"encoding/json"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/volatiletech/null/v8"
+)
+
+var (
+	jsonPtrType = reflect.TypeOf((*JSON)(nil))
+	doPtrType   = reflect.TypeOf((*DO)(nil))
+	daPtrType   = reflect.TypeOf((*DA)(nil))
+	timeType    = reflect.TypeOf(time.Time{})
+	float32Type = reflect.TypeOf(float32(0))
+	nullPkgPath = reflect.TypeOf(null.String{}).PkgPath()
+)
+
+// Get returns the value at path converted to T. T can be any bool, integer,
+// float or string type, including named ones such as string enums; time.Time
+// and []byte, read as by Time and Bytes; the null package types, invalid for
+// a JSON null; *JSON, *DO and *DA; and slices and map[string] of any of
+// these. Integers are range checked and fail with ErrNumberRange; other
+// failures are reported like GetInt. An empty path converts the whole
+// document; any other path without a [key] or [index] is ErrInvalidPath.
+
+func Get[T any](j *JSON, path string) (T, error) {
+	var r T
+
+	tokens, err := genericTokens(path)
+	if err != nil {
+		return r, err
+	}
+
+	v, err := j.lookup(tokens...)
+	if err != nil {
+		return r, err
+	}
+
+	rv, err := convertValue(v, reflect.TypeOf(&r).Elem(), tokens)
+	if err != nil {
+		return r, err
+	}
+
+	return rv.Interface().(T), nil
+}
+
+// GetOr returns Get's value, or def when Get fails.
+
+func GetOr[T any](j *JSON, path string, def T) T {
+	if r, err := Get[T](j, path); err == nil {
+		return r
+	}
+	return def
+}
+
+func GetSlice[T any](j *JSON, path string) ([]T, error) {
+	return Get[[]T](j, path)
+}
+
+func GetMap[V any](j *JSON, path string) (map[string]V, error) {
+	return Get[map[string]V](j, path)
+}
+
+// Set stores v at path like UpdatePathE, and reports a *PathError when the
+// path does not lead to an object or array. Unlike UpdatePath it does not pad
+// arrays: an index past the end is ErrIndexOutOfRange and m is left unchanged.
+// Named types such as string enums are stored as their underlying kind, and
+// slices and maps of any storable type are converted element by element;
+// anything else is ErrTypeMismatch.
+
+func Set[T any](j *JSON, path string, v T) error {
+	tokens, err := genericTokens(path)
+	if err != nil {
+		return err
+	}
+	if len(tokens) == 0 {
+		return pathError(tokens, ErrKeyNotFound)
+	}
+
+	parent := tokens[:len(tokens)-1]
+	pv, err := j.lookup(parent...)
+	if err != nil {
+		return err
+	}
+
+	if idx, ok := tokens[len(tokens)-1].(int); ok {
+		if arr, ok := asArray(pv); ok && (idx < 0 || idx >= arr.Size()) {
+			return pathError(tokens, ErrIndexOutOfRange)
+		}
+	}
+
+	sv, ok := storable(reflect.ValueOf(v))
+	if !ok {
+		return pathError(tokens, ErrTypeMismatch)
+	}
+
+	ok, err = j.UpdatePathE(path, sv)
+	if err != nil || ok {
+		return err
+	}
+
+	if _, ok := tokens[len(tokens)-1].(string); ok {
+		return pathError(parent, ErrNotObject)
+	}
+	return pathError(parent, ErrNotArray)
+}
+
+// storable converts v to a value Put keeps: named bools, integers, floats and
+// strings become their underlying kind, and slices and maps become
+// []interface{} and map[string]interface{}. ok is false for anything Put
+// would drop.
+
+func storable(v reflect.Value) (interface{}, bool) {
+	if !v.IsValid() {
+		return nil, true
+	}
+
+	t := v.Type()
+	switch t {
+	case jsonPtrType, doPtrType, daPtrType, timeType, reflect.TypeOf((*time.Time)(nil)),
+		reflect.TypeOf(JSON{}), reflect.TypeOf(DO{}), reflect.TypeOf(DA{}), reflect.TypeOf(NullJSON{}),
+		reflect.TypeOf(json.Number("")):
+		return v.Interface(), true
+	}
+
+	if v.CanInterface() {
+		if _, ok := toNumber(v.Interface()); ok {
+			return v.Interface(), true
+		}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return v.Bool(), true
+	case reflect.String:
+		return v.String(), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint(), true
+	case reflect.Float32:
+		return v.Convert(float32Type).Interface(), true
+	case reflect.Float64:
+		return v.Float(), true
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil, true
+		}
+		return storable(v.Elem())
+	case reflect.Interface:
+		return storable(v.Elem())
+	case reflect.Struct:
+		// the null package types
+		if t.PkgPath() == nullPkgPath {
+			return v.Interface(), true
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			if v.IsNil() {
+				return nil, true
+			}
+			return v.Bytes(), true
+		}
+
+		arr := make([]interface{}, v.Len())
+		for i := range arr {
+			each, ok := storable(v.Index(i))
+			if !ok {
+				return nil, false
+			}
+			arr[i] = each
+		}
+		return arr, true
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, false
+		}
+
+		obj := make(map[string]interface{}, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			each, ok := storable(iter.Value())
+			if !ok {
+				return nil, false
+			}
+			obj[iter.Key().String()] = each
+		}
+		return obj, true
+	}
+
+	return nil, false
+}
+
+// genericTokens tokenizes path and rejects a non-empty path that yields no
+// tokens, such as "a", which would otherwise address the whole document.
+
+func genericTokens(path string) ([]interface{}, error) {
+	tokens := PathTokenizer(path)
+	if len(tokens) == 0 && path != "" {
+		return nil, &PathError{Path: path, Err: ErrInvalidPath}
+	}
+	return tokens, nil
+}
+
+func convertValue(v interface{}, t reflect.Type, tokens []interface{}) (reflect.Value, error) {
+	mismatch := func() (reflect.Value, error) {
+		return reflect.Value{}, pathError(tokens, ErrTypeMismatch)
+	}
+
+	switch t {
+	case jsonPtrType:
+		r, ok := elementToJSON(v)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(r), nil
+	case doPtrType:
+		obj, ok := asObject(v)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(obj), nil
+	case daPtrType:
+		arr, ok := asArray(v)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(arr), nil
+	case timeType:
+		tm, ok := decodeTime(v, nil)
+		if !ok {
+			return mismatch()
+		}
+		return reflect.ValueOf(tm), nil
+	}
+
+	r := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Interface:
+		if v != nil {
+			rv := reflect.ValueOf(v)
+			if !rv.Type().AssignableTo(t) {
+				return mismatch()
+			}
+			r.Set(rv)
+		}
+	case reflect.Bool:
+		b, ok := toStrictBool(v)
+		if !ok {
+			return mismatch()
+		}
+		r.SetBool(b)
+	case reflect.String:
+		s, ok := toStrictString(v)
+		if !ok {
+			return mismatch()
+		}
+		r.SetString(s)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, ok := toStrictInt(v)
+		if !ok {
+			if _, ok := toStrictUint(v); ok {
+				return reflect.Value{}, pathError(tokens, ErrNumberRange)
+			}
+			return mismatch()
+		}
+		if r.OverflowInt(i) {
+			return reflect.Value{}, pathError(tokens, ErrNumberRange)
+		}
+		r.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u, ok := toStrictUint(v)
+		if !ok {
+			if i, ok := toStrictInt(v); ok && i < 0 {
+				return reflect.Value{}, pathError(tokens, ErrNumberRange)
+			}
+			return mismatch()
+		}
+		if r.OverflowUint(u) {
+			return reflect.Value{}, pathError(tokens, ErrNumberRange)
+		}
+		r.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, ok := toStrictFloat(v)
+		if !ok {
+			return mismatch()
+		}
+		if !math.IsInf(f, 0) && r.OverflowFloat(f) {
+			return reflect.Value{}, pathError(tokens, ErrNumberRange)
+		}
+		r.SetFloat(f)
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			s, ok := v.(string)
+			if !ok {
+				return mismatch()
+			}
+			b, ok := decodeBytes(s)
+			if !ok {
+				return mismatch()
+			}
+			r.SetBytes(b)
+			break
+		}
+
+		arr, ok := asArray(v)
+		if !ok {
+			return mismatch()
+		}
+
+		size := arr.Size()
+		r.Set(reflect.MakeSlice(t, size, size))
+		for idx := 0; idx < size; idx++ {
+			each, _ := arr.Get(idx)
+			ev, err := convertValue(each, t.Elem(), appendToken(tokens, idx))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			r.Index(idx).Set(ev)
+		}
+	case reflect.Map:
+		obj, ok := asObject(v)
+		if !ok || t.Key().Kind() != reflect.String {
+			return mismatch()
+		}
+
+		r.Set(reflect.MakeMapWithSize(t, obj.Len()))
+		for _, key := range obj.Keys() {
+			each, _ := obj.Get(key)
+			ev, err := convertValue(each, t.Elem(), appendToken(tokens, key))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			r.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), ev)
+		}
+	case reflect.Struct:
+		// null.String, null.Int64 and the like: a value field and Valid
+		valid, ok := t.FieldByName("Valid")
+		if !ok || t.NumField() != 2 || valid.Index[0] != 1 {
+			return mismatch()
+		}
+		if v == nil {
+			break
+		}
+
+		ev, err := convertValue(v, t.Field(0).Type, tokens)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		r.Field(0).Set(ev)
+		r.Field(1).SetBool(true)
+	default:
+		return mismatch()
+	}
+
+	return r, nil
+}
+
+func appendToken(tokens []interface{}, token interface{}) []interface{} {
+	return append(tokens[:len(tokens):len(tokens)], token)
+}
@@ -0,0 +1,189 @@
+package djson
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/volatiletech/null/v8"
+)
+
+type level string
+
+const genericDoc = `{
+	"port": 8080,
+	"big": 70000,
+	"neg": -1,
+	"ratio": 0.25,
+	"level": "debug",
+	"ids": [1, 2, 3],
+	"matrix": [[1, 2], [3]],
+	"limits": {"cpu": 2, "mem": 512},
+	"users": [{"name": "a"}, {"name": "b"}],
+	"nick": null
+}`
+
+func TestGet(t *testing.T) {
+	mJson := New().Parse(genericDoc)
+
+	if v, err := Get[uint16](mJson, `[port]`); err != nil || v != 8080 {
+		t.Errorf("Expected 8080, but got %d (%v)", v, err)
+	}
+	if v, err := Get[float32](mJson, `[ratio]`); err != nil || v != 0.25 {
+		t.Errorf("Expected 0.25, but got %v (%v)", v, err)
+	}
+	if v, err := Get[level](mJson, `[level]`); err != nil || v != level("debug") {
+		t.Errorf("Expected debug, but got %s (%v)", v, err)
+	}
+	if v, err := Get[*DO](mJson, `[users][1]`); err != nil || v.String("name") != "b" {
+		t.Errorf("Expected the second user, but got %v (%v)", v, err)
+	}
+	if v, err := Get[*JSON](mJson, `[limits]`); err != nil || v.Int("mem") != 512 {
+		t.Errorf("Expected the limits object, but got %v (%v)", v, err)
+	}
+	if v, err := Get[null.String](mJson, `[nick]`); err != nil || v.Valid {
+		t.Errorf("Expected an invalid null.String, but got %v (%v)", v, err)
+	}
+	if v, err := Get[null.Int32](mJson, `[port]`); err != nil || !v.Valid || v.Int32 != 8080 {
+		t.Errorf("Expected a valid null.Int32, but got %v (%v)", v, err)
+	}
+	if v := GetOr[int8](mJson, `[big]`, -1); v != -1 {
+		t.Errorf("Expected the default, but got %d", v)
+	}
+	if v := GetOr(mJson, `[port]`, 1); v != 8080 {
+		t.Errorf("Expected 8080, but got %d", v)
+	}
+}
+
+func TestGetErrors(t *testing.T) {
+	mJson := New().Parse(genericDoc)
+
+	tests := []struct {
+		err      error
+		expected error
+	}{
+		{second(Get[int16](mJson, `[big]`)), ErrNumberRange},
+		{second(Get[uint](mJson, `[neg]`)), ErrNumberRange},
+		{second(Get[int](mJson, `[ratio]`)), ErrTypeMismatch},
+		{second(Get[string](mJson, `[port]`)), ErrTypeMismatch},
+		{second(Get[int](mJson, `[missing]`)), ErrKeyNotFound},
+		{second(GetSlice[uint8](mJson, `[limits]`)), ErrTypeMismatch},
+		{second(GetSlice[string](mJson, `[ids]`)), ErrTypeMismatch},
+	}
+
+	for idx, test := range tests {
+		if !errors.Is(test.err, test.expected) {
+			t.Errorf("%d: Expected %v, but got %v", idx, test.expected, test.err)
+		}
+	}
+
+	var pathErr *PathError
+	if _, err := GetSlice[string](mJson, `[ids]`); !errors.As(err, &pathErr) || pathErr.Path != `["ids"][0]` {
+		t.Errorf("Expected the element path, but got %v", err)
+	}
+}
+
+func TestGetSliceAndMap(t *testing.T) {
+	mJson := New().Parse(genericDoc)
+
+	if v, err := GetSlice[int32](mJson, `[ids]`); err != nil || !reflect.DeepEqual(v, []int32{1, 2, 3}) {
+		t.Errorf("Expected [1 2 3], but got %v (%v)", v, err)
+	}
+	if v, err := GetSlice[[]int](mJson, `[matrix]`); err != nil || !reflect.DeepEqual(v, [][]int{{1, 2}, {3}}) {
+		t.Errorf("Expected [[1 2] [3]], but got %v (%v)", v, err)
+	}
+	if v, err := GetMap[uint64](mJson, `[limits]`); err != nil || !reflect.DeepEqual(v, map[string]uint64{"cpu": 2, "mem": 512}) {
+		t.Errorf("Expected the limits, but got %v (%v)", v, err)
+	}
+	if v, err := GetSlice[*DO](mJson, `[users]`); err != nil || len(v) != 2 || v[0].String("name") != "a" {
+		t.Errorf("Expected two users, but got %v (%v)", v, err)
+	}
+	if v, err := GetSlice[interface{}](mJson, `[ids]`); err != nil || len(v) != 3 {
+		t.Errorf("Expected three elements, but got %v (%v)", v, err)
+	}
+}
+
+func TestSet(t *testing.T) {
+	mJson := New().Parse(genericDoc)
+
+	if err := Set(mJson, `[limits][disk]`, uint32(10)); err != nil || mJson.IntPath(`[limits][disk]`) != 10 {
+		t.Errorf("Expected disk to be set, but got %v", err)
+	}
+
+	if err := Set(mJson, `[level][name]`, "x"); !errors.Is(err, ErrNotObject) {
+		t.Errorf("Expected ErrNotObject, but got %v", err)
+	}
+
+	if err := Set(mJson, `[missing][name]`, "x"); !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("Expected ErrKeyNotFound, but got %v", err)
+	}
+
+	aJson := New().Parse(`{"c":[1,2,3]}`)
+	for _, path := range []string{`[c][9]`, `[c][3]`, `[c][-1]`, `[c][9][x]`} {
+		if err := Set(aJson, path, 1); !errors.Is(err, ErrIndexOutOfRange) {
+			t.Errorf("%s: Expected ErrIndexOutOfRange, but got %v", path, err)
+		}
+	}
+	if result := aJson.ToString(); result != `{"c":[1,2,3]}` {
+		t.Errorf("Expected the array to be unchanged, but got %s", result)
+	}
+	if err := Set(aJson, `[c][2]`, 9); err != nil || aJson.ToString() != `{"c":[1,2,9]}` {
+		t.Errorf("Expected the last element to be set, but got %s (%v)", aJson.ToString(), err)
+	}
+
+	if err := Set(aJson, `c`, 1); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, but got %v", err)
+	}
+}
+
+func TestSetNamedTypes(t *testing.T) {
+	type priority int16
+
+	mJson := New().Parse(`{"a":{}}`)
+
+	if err := Set(mJson, `[a][level]`, level("warn")); err != nil {
+		t.Errorf("Expected a named string to be set, but got %v", err)
+	}
+	if err := Set(mJson, `[a][prio]`, priority(-3)); err != nil {
+		t.Errorf("Expected a named int to be set, but got %v", err)
+	}
+	if err := Set(mJson, `[a][limits]`, map[string]int{"cpu": 2}); err != nil {
+		t.Errorf("Expected a map to be set, but got %v", err)
+	}
+	if err := Set(mJson, `[a][levels]`, []level{"x", "y"}); err != nil {
+		t.Errorf("Expected a slice of named strings to be set, but got %v", err)
+	}
+
+	expected := `{"a":{"level":"warn","levels":["x","y"],"limits":{"cpu":2},"prio":-3}}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	for _, v := range []interface{}{make(chan int), map[int]string{1: "a"}, struct{ A int }{1}, []func(){nil}} {
+		if err := Set(mJson, `[a][bad]`, v); !errors.Is(err, ErrTypeMismatch) {
+			t.Errorf("%T: Expected ErrTypeMismatch, but got %v", v, err)
+		}
+	}
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected the tree to be unchanged, but got %s", result)
+	}
+}
+
+func TestGetIntRange(t *testing.T) {
+	mJson := New().Parse(`{"big":18446744073709551615}`)
+
+	if v, err := Get[int64](mJson, `[big]`); !errors.Is(err, ErrNumberRange) {
+		t.Errorf("Expected ErrNumberRange, but got %d (%v)", v, err)
+	}
+}
+
+func TestGetInvalidPath(t *testing.T) {
+	mJson := New().Parse(`{"a":1}`)
+
+	if v, err := Get[*DO](mJson, `a`); !errors.Is(err, ErrInvalidPath) {
+		t.Errorf("Expected ErrInvalidPath, but got %v (%v)", v, err)
+	}
+	if _, err := Get[*DO](mJson, ``); err != nil {
+		t.Errorf("Expected an empty path to convert the document, but got %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package djson
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrKeyNotFound     = errors.New("djson: key not found")
+	ErrTypeMismatch    = errors.New("djson: value has a different type")
+	ErrNotObject       = errors.New("djson: value is not an object")
+	ErrNotArray        = errors.New("djson: value is not an array")
+	ErrIndexOutOfRange = errors.New("djson: index out of range")
+	ErrInvalidPath     = errors.New("djson: invalid path")
+)
+
+// PathError reports which part of a path a Get accessor failed at. Err is one
+// of the sentinel errors above.
+type PathError struct {
+	Path string
+	Err  error
+}
+
+func (e *PathError) Error() string {
+	if e.Path == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s at %s", e.Err, e.Path)
+}
+
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// pathError renders tokens in path syntax, such as ["items"][2].
+
+func pathError(tokens []interface{}, err error) error {
+	var sb strings.Builder
+	for _, token := range tokens {
+		sb.WriteByte('[')
+		if s, ok := token.(string); ok {
+			sb.WriteString(strconv.Quote(s))
+		} else {
+			fmt.Fprint(&sb, token)
+		}
+		sb.WriteByte(']')
+	}
+
+	return &PathError{Path: sb.String(), Err: err}
+}
+
+// lookup returns the value at the tokens, which are object keys and array
+// indexes as PathTokenizer produces them.
+
+func (m *JSON) lookup(tokens ...interface{}) (interface{}, error) {
+	var cur interface{} = m.Interface()
+
+	for idx, token := range tokens {
+		switch tkey := token.(type) {
+		case string:
+			obj, ok := asObject(cur)
+			if !ok {
+				return nil, pathError(tokens[:idx], ErrNotObject)
+			}
+
+			value, ok := obj.Get(tkey)
+			if !ok {
+				return nil, pathError(tokens[:idx+1], ErrKeyNotFound)
+			}
+			cur = value
+		default:
+			i, ok := getIntBase(token)
+			if !ok {
+				return nil, pathError(tokens[:idx+1], ErrTypeMismatch)
+			}
+
+			arr, ok := asArray(cur)
+			if !ok {
+				return nil, pathError(tokens[:idx], ErrNotArray)
+			}
+
+			value, ok := arr.Get(int(i))
+			if !ok {
+				return nil, pathError(tokens[:idx+1], ErrIndexOutOfRange)
+			}
+			cur = value
+		}
+	}
+
+	return cur, nil
+}
+
+func asObject(v interface{}) (*DO, bool) {
+	switch t := v.(type) {
+	case *DO:
+		loaded(t)
+		return t, t != nil
+	case DO:
+		return &t, true
+	}
+	return nil, false
+}
+
+func asArray(v interface{}) (*DA, bool) {
+	switch t := v.(type) {
+	case *DA:
+		loaded(t)
+		return t, t != nil
+	case DA:
+		return &t, true
+	}
+	return nil, false
+}
+
+// The Get accessors take an optional object key or array index, like Int and
+// String, and fail with a *PathError instead of returning a zero value.
+// GetInt accepts integers only, GetFloat any number, GetBool booleans and
+// GetString strings.
+
+func (m *JSON) GetInt(key ...interface{}) (int64, error) {
+	return getTyped(m, key, toStrictInt)
+}
+
+func (m *JSON) GetFloat(key ...interface{}) (float64, error) {
+	return getTyped(m, key, toStrictFloat)
+}
+
+func (m *JSON) GetBool(key ...interface{}) (bool, error) {
+	return getTyped(m, key, toStrictBool)
+}
+
+func (m *JSON) GetString(key ...interface{}) (string, error) {
+	return getTyped(m, key, toStrictString)
+}
+
+func (m *JSON) GetIntPath(path string) (int64, error) {
+	return getTyped(m, PathTokenizer(path), toStrictInt)
+}
+
+func (m *JSON) GetFloatPath(path string) (float64, error) {
+	return getTyped(m, PathTokenizer(path), toStrictFloat)
+}
+
+func (m *JSON) GetBoolPath(path string) (bool, error) {
+	return getTyped(m, PathTokenizer(path), toStrictBool)
+}
+
+func (m *JSON) GetStringPath(path string) (string, error) {
+	return getTyped(m, PathTokenizer(path), toStrictString)
+}
+
+func getTyped[T any](m *JSON, tokens []interface{}, convert func(interface{}) (T, bool)) (T, error) {
+	var zero T
+
+	v, err := m.lookup(tokens...)
+	if err != nil {
+		return zero, err
+	}
+
+	r, ok := convert(v)
+	if !ok {
+		return zero, pathError(tokens, ErrTypeMismatch)
+	}
+	return r, nil
+}
+
+func toStrictInt(v interface{}) (int64, bool) {
+	if n, ok := v.(Number); ok {
+		if !n.IsInt() {
+			return 0, false
+		}
+		i, err := n.Int64()
+		return i, err == nil
+	}
+
+	if !IsIntType(v) {
+		return 0, false
+	}
+	return getIntBase(v)
+}
+
+func toStrictFloat(v interface{}) (float64, bool) {
+	if _, ok := v.(Number); !ok && !IsIntType(v) && !IsFloatType(v) {
+		return 0, false
+	}
+	return getFloatBase(v)
+}
+
+func toStrictBool(v interface{}) (bool, bool) {
+	b, ok := v.(bool)
+	return b, ok
+}
+
+func toStrictString(v interface{}) (string, bool) {
+	s, ok := v.(string)
+	return s, ok
+}
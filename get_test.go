@@ -0,0 +1,90 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+const getDoc = `{"name":"djson","stars":42,"ratio":0.5,"active":true,"count":"7","tags":["a","b"],"owner":{"id":3}}`
+
+func TestGetAccessors(t *testing.T) {
+	mJson := New().Parse(getDoc)
+
+	if v, err := mJson.GetInt("stars"); err != nil || v != 42 {
+		t.Errorf("Expected 42, but got %d (%v)", v, err)
+	}
+	if v, err := mJson.GetFloat("stars"); err != nil || v != 42 {
+		t.Errorf("Expected 42, but got %v (%v)", v, err)
+	}
+	if v, err := mJson.GetFloat("ratio"); err != nil || v != 0.5 {
+		t.Errorf("Expected 0.5, but got %v (%v)", v, err)
+	}
+	if v, err := mJson.GetBool("active"); err != nil || !v {
+		t.Errorf("Expected true, but got %v (%v)", v, err)
+	}
+	if v, err := mJson.GetString("name"); err != nil || v != "djson" {
+		t.Errorf("Expected djson, but got %s (%v)", v, err)
+	}
+	if v, err := mJson.GetStringPath(`[tags][1]`); err != nil || v != "b" {
+		t.Errorf("Expected b, but got %s (%v)", v, err)
+	}
+	if v, err := mJson.GetIntPath(`[owner][id]`); err != nil || v != 3 {
+		t.Errorf("Expected 3, but got %d (%v)", v, err)
+	}
+	if v, err := NewInt(5).GetInt(); err != nil || v != 5 {
+		t.Errorf("Expected 5, but got %d (%v)", v, err)
+	}
+}
+
+func TestGetAccessorErrors(t *testing.T) {
+	mJson := New().Parse(getDoc)
+
+	tests := []struct {
+		err      error
+		expected error
+		path     string
+	}{
+		{second(mJson.GetInt("missing")), ErrKeyNotFound, `["missing"]`},
+		{second(mJson.GetInt("count")), ErrTypeMismatch, `["count"]`},
+		{second(mJson.GetInt("ratio")), ErrTypeMismatch, `["ratio"]`},
+		{second(mJson.GetBool("stars")), ErrTypeMismatch, `["stars"]`},
+		{second(mJson.GetString(0)), ErrNotArray, ``},
+		{second(mJson.GetStringPath(`[tags][5]`)), ErrIndexOutOfRange, `["tags"][5]`},
+		{second(mJson.GetStringPath(`[name][first]`)), ErrNotObject, `["name"]`},
+		{second(mJson.GetIntPath(`[owner][name]`)), ErrKeyNotFound, `["owner"]["name"]`},
+		{second(mJson.GetFloatPath(`[tags][0]`)), ErrTypeMismatch, `["tags"][0]`},
+	}
+
+	for idx, test := range tests {
+		if !errors.Is(test.err, test.expected) {
+			t.Errorf("%d: Expected %v, but got %v", idx, test.expected, test.err)
+			continue
+		}
+
+		var pathErr *PathError
+		if !errors.As(test.err, &pathErr) || pathErr.Path != test.path {
+			t.Errorf("%d: Expected path %s, but got %v", idx, test.path, test.err)
+		}
+	}
+}
+
+func second[T any](_ T, err error) error {
+	return err
+}
+
+func TestDefaultValuesDoNotPanic(t *testing.T) {
+	mJson := New().Parse(getDoc)
+
+	if result := mJson.String("missing", 5); result != "5" {
+		t.Errorf("Expected 5, but got %s", result)
+	}
+	if result := mJson.String("missing", nil); result != "" {
+		t.Errorf("Expected an empty string, but got %s", result)
+	}
+	if result := mJson.Bool("missing", "true"); !result {
+		t.Errorf("Expected true")
+	}
+	if result := mJson.Bool("missing", 3.5); result {
+		t.Errorf("Expected false")
+	}
+}
@@ -0,0 +1,348 @@
+package djson
+
+import (
+	"unicode/utf8"
+)
+
+// lazySource holds the raw bytes of an object or array that has not been
+// decoded yet, with the options to decode it with. The bytes were validated
+// when the document was parsed.
+type lazySource struct {
+	data []byte
+	opts ParseOptions
+}
+
+// ParseLazy parses doc with ParseOptions.Lazy set. Only the top level is
+// decoded; nested objects and arrays are decoded when first accessed, and
+// ToString writes untouched ones as they appear in doc, without white space.
+// That text is not normalised: "<" stays unescaped, 1.0 and 2e2 keep their
+// spelling and duplicate keys are all kept, where an eager parse writes
+// "\u003c", 1 and 200 and keeps the last duplicate.
+// A lazy document must not be read from several goroutines at once, because
+// reads decode and store subtrees.
+
+func (m *JSON) ParseLazy(doc []byte) (*JSON, error) {
+	return m.ParseWithOptions(doc, ParseOptions{Lazy: true})
+}
+
+// parseLazyDocument validates the whole document against the limits, then
+// decodes the top level only.
+
+func (p *parser) parseLazyDocument() (interface{}, error) {
+	if err := p.skipValue(); err != nil {
+		return nil, err
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.data) {
+		return nil, p.errorAt(p.pos, "%s after top-level value", p.describe(p.pos))
+	}
+
+	p.pos = 0
+	p.opts = ParseOptions{
+		DuplicateKeys: p.opts.DuplicateKeys,
+		Ordered:       p.opts.Ordered,
+		Lossless:      p.opts.Lossless,
+		Lazy:          true,
+	}
+
+	return p.parseValue()
+}
+
+func (p *parser) lazyValue() (interface{}, error) {
+	start := p.pos
+	if err := p.skipValue(); err != nil {
+		return nil, err
+	}
+
+	src := &lazySource{data: p.data[start:p.pos], opts: p.opts}
+	if p.data[start] == '{' {
+		return &DO{ordered: p.opts.Ordered || orderedObject, lazy: src}, nil
+	}
+	return &DA{lazy: src}, nil
+}
+
+// Load decodes an object of a lazy document that has not been decoded yet.
+// Accessors that return a nested object or array load it first, but the
+// values in Map are left as they are, so load them before using their Map.
+// If decoding fails the object stays empty and Load keeps returning the error.
+
+func (m *DO) Load() error {
+	m.load()
+	return m.loadErr
+}
+
+// Load decodes an array of a lazy document, like DO.Load.
+
+func (m *DA) Load() error {
+	m.load()
+	return m.loadErr
+}
+
+func (m *DO) load() {
+	if m.lazy == nil {
+		return
+	}
+
+	src := m.lazy
+	m.lazy = nil
+
+	p := newParser(src.data)
+	p.opts = src.opts
+	p.opts.Ordered = m.ordered
+
+	obj, err := p.parseObject()
+	if err != nil {
+		obj, m.loadErr = NewDO(), err
+	}
+
+	m.Map = obj.Map
+	if m.ordered {
+		m.keys = obj.keys
+	}
+	if m.coercion != CoercionDefault {
+		m.SetCoercion(m.coercion)
+	}
+}
+
+func (m *DA) load() {
+	if m.lazy == nil {
+		return
+	}
+
+	src := m.lazy
+	m.lazy = nil
+
+	p := newParser(src.data)
+	p.opts = src.opts
+
+	arr, err := p.parseArray()
+	if err != nil {
+		arr, m.loadErr = NewDA(), err
+	}
+
+	m.Element = arr.Element
+	if m.coercion != CoercionDefault {
+		m.SetCoercion(m.coercion)
+	}
+}
+
+// loaded decodes v first when it is a lazy object or array.
+
+func loaded(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *DO:
+		if t != nil {
+			t.load()
+		}
+	case *DA:
+		if t != nil {
+			t.load()
+		}
+	}
+
+	return v
+}
+
+// skipValue checks one value the way parseValue would, without building it.
+
+func (p *parser) skipValue() error {
+	p.skipSpace()
+
+	if p.pos >= len(p.data) {
+		return p.errorAt(p.pos, "unexpected end of input looking for value")
+	}
+
+	switch c := p.data[p.pos]; {
+	case c == '{':
+		return p.skipObject()
+	case c == '[':
+		return p.skipArray()
+	case c == '"':
+		return p.skipString()
+	case c == '-':
+		p.pos++
+		return p.scanDecimal()
+	case c >= '0' && c <= '9':
+		return p.scanDecimal()
+	case c == 't':
+		return p.expectLiteral("true")
+	case c == 'f':
+		return p.expectLiteral("false")
+	case c == 'n':
+		return p.expectLiteral("null")
+	}
+
+	return p.errorAt(p.pos, "%s looking for beginning of value", p.describe(p.pos))
+}
+
+func (p *parser) skipObject() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer func() { p.depth-- }()
+
+	p.pos++ // '{'
+
+	var seen map[string]bool
+	if p.opts.DuplicateKeys == DuplicateKeyError {
+		seen = make(map[string]bool)
+	}
+
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == '}' {
+		p.pos++
+		return nil
+	}
+
+	for n := 1; ; n++ {
+		p.skipSpace()
+		keyPos := p.pos
+
+		if p.pos >= len(p.data) || p.data[p.pos] != '"' {
+			return p.errorAt(p.pos, "%s looking for beginning of object key string", p.describe(p.pos))
+		}
+
+		if seen != nil {
+			key, err := p.parseString()
+			if err != nil {
+				return err
+			}
+			if seen[key] {
+				return p.limitAt(keyPos, ErrDuplicateKey, "duplicate key %q", key)
+			}
+			seen[key] = true
+		} else if err := p.skipString(); err != nil {
+			return err
+		}
+
+		if p.opts.MaxKeys > 0 && n > p.opts.MaxKeys {
+			return p.limitAt(keyPos, ErrMaxKeys, "object exceeds limit of %d keys", p.opts.MaxKeys)
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+			return p.errorAt(p.pos, "%s after object key", p.describe(p.pos))
+		}
+		p.pos++
+
+		if err := p.skipValue(); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return p.errorAt(p.pos, "unexpected end of input in object")
+		}
+
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case '}':
+			p.pos++
+			return nil
+		default:
+			return p.errorAt(p.pos, "%s after object key:value pair", p.describe(p.pos))
+		}
+	}
+}
+
+func (p *parser) skipArray() error {
+	if err := p.enter(); err != nil {
+		return err
+	}
+	defer func() { p.depth-- }()
+
+	p.pos++ // '['
+
+	p.skipSpace()
+	if p.pos < len(p.data) && p.data[p.pos] == ']' {
+		p.pos++
+		return nil
+	}
+
+	for n := 1; ; n++ {
+		if p.opts.MaxArrayLen > 0 && n > p.opts.MaxArrayLen {
+			p.skipSpace()
+			return p.limitAt(p.pos, ErrMaxArrayLen, "array exceeds limit of %d elements", p.opts.MaxArrayLen)
+		}
+
+		if err := p.skipValue(); err != nil {
+			return err
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.data) {
+			return p.errorAt(p.pos, "unexpected end of input in array")
+		}
+
+		switch p.data[p.pos] {
+		case ',':
+			p.pos++
+		case ']':
+			p.pos++
+			return nil
+		default:
+			return p.errorAt(p.pos, "%s after array element", p.describe(p.pos))
+		}
+	}
+}
+
+func (p *parser) skipString() error {
+	start := p.pos
+	p.pos++ // '"'
+
+	n := 0
+	for {
+		if p.pos >= len(p.data) {
+			return p.errorAt(start, "unterminated string")
+		}
+
+		switch c := p.data[p.pos]; {
+		case c == '"':
+			p.pos++
+			return p.checkStringLen(start, n)
+		case c < 0x20:
+			return p.errorAt(p.pos, "invalid character %q in string literal", rune(c))
+		case c == '\\':
+			r, err := p.parseEscape()
+			if err != nil {
+				return err
+			}
+			n += utf8.RuneLen(r)
+		default:
+			p.pos++
+			n++
+		}
+	}
+}
+
+// appendCompact appends validated JSON text without its insignificant white
+// space.
+
+func appendCompact(buf, data []byte) []byte {
+	inString := false
+
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+
+		switch {
+		case inString:
+			if c == '\\' {
+				buf = append(buf, c)
+				i++
+				c = data[i]
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			continue
+		}
+
+		buf = append(buf, c)
+	}
+
+	return buf
+}
@@ -0,0 +1,129 @@
+package djson
+
+import (
+	"errors"
+	"testing"
+)
+
+const lazyDoc = `{
+	"event": "push",
+	"repository": {"name": "djson", "owner": {"login": "GoHJ7"}, "topics": ["json", "go"]},
+	"commits": [ {"id": "a1", "message": "first"}, {"id": "b2", "message": "second \"quoted\""} ],
+	"sender": {"z": 1, "a": 2}
+}`
+
+func TestParseLazy(t *testing.T) {
+	mJson, err := New().ParseLazy([]byte(lazyDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if mJson._Object.Map["repository"].(*DO).lazy == nil || mJson._Object.Map["commits"].(*DA).lazy == nil {
+		t.Fatalf("Expected nested values to stay undecoded")
+	}
+
+	if result := mJson.StringPath(`[repository][owner][login]`); result != "GoHJ7" {
+		t.Errorf("Expected GoHJ7, but got %s", result)
+	}
+
+	if mJson._Object.Map["repository"].(*DO).lazy != nil || mJson._Object.Map["commits"].(*DA).lazy == nil {
+		t.Errorf("Expected only the repository branch to be decoded")
+	}
+
+	expected := `{"commits":[{"id":"a1","message":"first"},{"id":"b2","message":"second \"quoted\""}],"event":"push","repository":{"name":"djson","owner":{"login":"GoHJ7"},"topics":["json","go"]},"sender":{"z":1,"a":2}}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if !mJson.PutObjectToPath(`[commits][1]`, "id", "c3") {
+		t.Fatalf("Expected PutObjectToPath to succeed")
+	}
+
+	if result := mJson.StringPath(`[commits][1][id]`); result != "c3" {
+		t.Errorf("Expected c3, but got %s", result)
+	}
+
+	if mJson._Object.Map["sender"].(*DO).lazy == nil {
+		t.Errorf("Expected sender to stay undecoded")
+	}
+
+	eager := New().Parse(lazyDoc)
+	eager.PutObjectToPath(`[commits][1]`, "id", "c3")
+	if !mJson.Equal(eager) {
+		t.Errorf("Expected %s, but got %s", eager.ToString(), mJson.ToString())
+	}
+
+	if result := mJson.Format(FormatOptions{}); result != eager.ToString() {
+		t.Errorf("Expected %s, but got %s", eager.ToString(), result)
+	}
+}
+
+func TestParseLazyErrors(t *testing.T) {
+	docs := []string{
+		`{"a": {"b": [1, 2,]}}`,
+		`{"a": {"b": "open}}`,
+		`{"a": [01]}`,
+		`{"a": {}} x`,
+	}
+
+	for _, doc := range docs {
+		_, err := New().ParseLazy([]byte(doc))
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%s: Expected *SyntaxError, but got %v", doc, err)
+		}
+	}
+
+	limits := map[string]ParseOptions{
+		`{"a":{"b":{"c":1}}}`:  {Lazy: true, MaxDepth: 2},
+		`{"a":{"b":1,"b":2}}`:  {Lazy: true, DuplicateKeys: DuplicateKeyError},
+		`{"a":[1,2,3]}`:        {Lazy: true, MaxArrayLen: 2},
+		`{"a":{"k":"abcdef"}}`: {Lazy: true, MaxStringLen: 5},
+	}
+
+	for doc, opts := range limits {
+		_, err := New().ParseWithOptions([]byte(doc), opts)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) || serr.Err == nil {
+			t.Errorf("%s: Expected limit error, but got %v", doc, err)
+		}
+	}
+
+	mJson, err := New().ParseWithOptions([]byte(`{"a":{"b":1,"b":2}}`), ParseOptions{Lazy: true, DuplicateKeys: DuplicateKeyFirstWins})
+	if err != nil || mJson.IntPath(`[a][b]`) != 1 {
+		t.Errorf("Expected 1, but got %s (%v)", mJson.ToString(), err)
+	}
+}
+
+func TestLazyContainersAreMaterialised(t *testing.T) {
+	mJson, err := New().ParseLazy([]byte(lazyDoc))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, ok := mJson.Object("repository")
+	if !ok || repo._Object.Map == nil {
+		t.Fatalf("Expected the repository object to be decoded")
+	}
+	repo._Object.Map["stars"] = 1
+
+	if result := mJson.IntPath(`[repository][stars]`); result != 1 {
+		t.Errorf("Expected 1, but got %d", result)
+	}
+
+	if v, err := Get[*DA](mJson, `[commits]`); err != nil || len(v.Element) != 2 {
+		t.Errorf("Expected two decoded commits, but got %v (%v)", v, err)
+	}
+
+	if err := mJson._Object.Map["sender"].(*DO).Load(); err != nil {
+		t.Errorf("Expected sender to load, but got %v", err)
+	}
+
+	bad := &DO{lazy: &lazySource{data: []byte(`{"a":`)}}
+	if err := bad.Load(); err == nil || bad.Map == nil {
+		t.Errorf("Expected the decoding error to be kept, but got %v", err)
+	}
+	if err := bad.Load(); err == nil {
+		t.Errorf("Expected Load to keep returning the error")
+	}
+}
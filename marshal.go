@@ -0,0 +1,102 @@
+package djson
+
+import (
+	"bytes"
+	"errors"
+)
+
+// ErrUnmarshalType is returned when UnmarshalJSON on a DO or DA receives a
+// value of another JSON type.
+var ErrUnmarshalType = errors.New("djson: JSON value does not match the target type")
+
+// The methods below let JSON, DO and DA be used as struct fields, map values
+// and slice elements with encoding/json and goccy/go-json. Marshalers use
+// value receivers so values that are not addressable are covered too.
+
+// MarshalJSON writes the document as JSON text. Unlike ToString, a STRING
+// document is written quoted.
+
+func (m JSON) MarshalJSON() ([]byte, error) {
+	return marshalJSON(m.Interface())
+}
+
+// UnmarshalJSON replaces the document with the value in data, keeping the
+// ordered and lossless settings of m.
+
+func (m *JSON) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalJSON(data, m._Ordered, m._Lossless)
+	if err != nil {
+		return err
+	}
+
+	*m = JSON{_Ordered: m._Ordered, _Lossless: m._Lossless, _Coercion: m._Coercion}
+	m.setElement(v)
+	return nil
+}
+
+// MarshalText is MarshalJSON, so a document can be used wherever an
+// encoding.TextMarshaler is expected.
+
+func (m JSON) MarshalText() ([]byte, error) {
+	return m.MarshalJSON()
+}
+
+func (m *JSON) UnmarshalText(text []byte) error {
+	return m.UnmarshalJSON(text)
+}
+
+func (m DO) MarshalJSON() ([]byte, error) {
+	return marshalJSON(&m)
+}
+
+func (m *DO) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalJSON(data, m.ordered, false)
+	if err != nil {
+		return err
+	}
+
+	obj, ok := v.(*DO)
+	if !ok {
+		return ErrUnmarshalType
+	}
+
+	*m = *obj
+	return nil
+}
+
+func (m DA) MarshalJSON() ([]byte, error) {
+	return marshalJSON(&m)
+}
+
+func (m *DA) UnmarshalJSON(data []byte) error {
+	v, err := unmarshalJSON(data, false, false)
+	if err != nil {
+		return err
+	}
+
+	arr, ok := v.(*DA)
+	if !ok {
+		return ErrUnmarshalType
+	}
+
+	*m = *arr
+	return nil
+}
+
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+
+	if err := newValueEncoder(&buf).encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func unmarshalJSON(data []byte, ordered, lossless bool) (interface{}, error) {
+	p := newParser(data)
+	p.opts.Ordered = ordered
+	p.opts.Lossless = lossless || losslessNumber
+
+	return p.parseDocument()
+}
@@ -0,0 +1,75 @@
+package djson
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+type marshalEnvelope struct {
+	ID      int              `json:"id"`
+	Payload *JSON            `json:"payload"`
+	Name    JSON             `json:"name"`
+	Meta    DO               `json:"meta"`
+	List    *DA              `json:"list"`
+	Extra   map[string]*JSON `json:"extra"`
+	Items   []JSON           `json:"items"`
+	Missing *JSON            `json:"missing"`
+}
+
+func TestMarshalJSON(t *testing.T) {
+	doc := `{"id":7,"payload":{"a":[1,"<b>"]},"name":"Ricardo","meta":{"k":1.5},"list":[true,null],"extra":{"x":12},"items":["s",{"n":null}],"missing":null}`
+
+	marshalers := map[string]func(interface{}) ([]byte, error){"encoding/json": stdjson.Marshal, "go-json": json.Marshal}
+	unmarshalers := map[string]func([]byte, interface{}) error{"encoding/json": stdjson.Unmarshal, "go-json": json.Unmarshal}
+
+	for name, unmarshal := range unmarshalers {
+		var env marshalEnvelope
+		if err := unmarshal([]byte(doc), &env); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if env.Payload.StringPath(`[a][1]`) != "<b>" || env.Name.ToString() != "Ricardo" || env.Extra["x"].Int() != 12 {
+			t.Errorf("%s: unexpected values %+v", name, env)
+		}
+
+		for mname, marshal := range marshalers {
+			out, err := marshal(env)
+			if err != nil {
+				t.Fatalf("%s/%s: %v", name, mname, err)
+			}
+
+			expected := `{"id":7,"payload":{"a":[1,"\u003cb\u003e"]},"name":"Ricardo","meta":{"k":1.5},"list":[true,null],"extra":{"x":12},"items":["s",{"n":null}],"missing":null}`
+			if string(out) != expected {
+				t.Errorf("%s/%s: Expected %s, but got %s", name, mname, expected, out)
+			}
+		}
+	}
+}
+
+func TestUnmarshalJSONErrors(t *testing.T) {
+	var obj DO
+	if err := obj.UnmarshalJSON([]byte(`[1]`)); !errors.Is(err, ErrUnmarshalType) {
+		t.Errorf("Expected ErrUnmarshalType, but got %v", err)
+	}
+
+	var arr DA
+	if err := stdjson.Unmarshal([]byte(`{"a":1}`), &arr); !errors.Is(err, ErrUnmarshalType) {
+		t.Errorf("Expected ErrUnmarshalType, but got %v", err)
+	}
+
+	mJson := New().Parse(`{"a":1}`)
+	if err := mJson.UnmarshalJSON([]byte(`{"a":`)); err == nil {
+		t.Errorf("Expected syntax error")
+	}
+
+	if err := mJson.UnmarshalText([]byte(`"text"`)); err != nil || mJson.ToString() != "text" {
+		t.Errorf("Expected text, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	if out, _ := mJson.MarshalText(); string(out) != `"text"` {
+		t.Errorf("Expected \"text\", but got %s", out)
+	}
+}
@@ -0,0 +1,541 @@
+package djson
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// MessagePack mapping:
+//
+//	nil, bool, str           <-> NULL, BOOL, STRING
+//	int family               <-> INT; a uint64 above MaxInt64 stays unsigned
+//	float32, float64         <-> FLOAT; NaN and ±Inf decode as null or a string
+//	                             under NonFiniteNull and NonFiniteString and
+//	                             fail with ErrNonFiniteFloat otherwise
+//	array, map               <-> DA, DO; non-string map keys are formatted as text
+//	bin                       -> STRING holding the bytes in the SetBytesEncoding base64
+//	ext                      <-> an object {"$ext": <type>, "$data": <base64>}
+//
+// Strings are always written as str, never as bin, so JSON -> msgpack -> JSON
+// returns the same document. Lossless Numbers are written as integers when
+// they fit 64 bits and as float64 otherwise.
+
+var (
+	ErrInvalidMsgpack     = errors.New("djson: invalid msgpack data")
+	ErrUnsupportedMsgpack = errors.New("djson: value has no msgpack form")
+)
+
+const msgpackMaxDepth = 10000
+
+// MarshalMsgpack returns the MessagePack encoding of the document.
+
+func (m *JSON) MarshalMsgpack() ([]byte, error) {
+	return appendMsgpack(make([]byte, 0, 256), m.Interface())
+}
+
+// WriteMsgpack writes the MessagePack encoding of the document to w.
+
+func (m *JSON) WriteMsgpack(w io.Writer) (int64, error) {
+	buf, err := m.MarshalMsgpack()
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := w.Write(buf)
+	return int64(n), err
+}
+
+// ParseMsgpack decodes exactly one MessagePack value into m.
+
+func (m *JSON) ParseMsgpack(data []byte) (*JSON, error) {
+	if m._Type != NULL {
+		return m, ErrNotNull
+	}
+
+	r := bytes.NewReader(data)
+	d := &msgpackReader{r: r, ordered: m._Ordered, limit: int64(len(data))}
+
+	v, err := d.value()
+	if err != nil {
+		return m, err
+	}
+
+	if r.Len() > 0 {
+		return m, fmt.Errorf("%w: %d trailing bytes at offset %d", ErrInvalidMsgpack, r.Len(), d.off)
+	}
+
+	m.setElement(v)
+	return m, nil
+}
+
+// MsgpackDecoder reads a stream of consecutive MessagePack values.
+type MsgpackDecoder struct {
+	Ordered bool
+
+	r   *bufio.Reader
+	off int64
+}
+
+func NewMsgpackDecoder(r io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{
+		r: bufio.NewReader(r),
+	}
+}
+
+// Next returns the next value, or io.EOF when the stream ends between values.
+
+func (d *MsgpackDecoder) Next() (*JSON, error) {
+	if _, err := d.r.Peek(1); err != nil {
+		return nil, err
+	}
+
+	md := &msgpackReader{r: d.r, off: d.off, ordered: d.Ordered, limit: -1}
+	v, err := md.value()
+	d.off = md.off
+	if err != nil {
+		return nil, err
+	}
+
+	ret := New()
+	ret.setElement(v)
+	return ret, nil
+}
+
+type msgpackReader struct {
+	r interface {
+		io.Reader
+		io.ByteReader
+	}
+	off     int64
+	depth   int
+	ordered bool
+	limit   int64 // total input size, -1 when streaming
+}
+
+func (d *msgpackReader) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("%w: %s at offset %d", ErrInvalidMsgpack, fmt.Sprintf(format, args...), d.off)
+}
+
+func (d *msgpackReader) byte() (byte, error) {
+	c, err := d.r.ReadByte()
+	if err != nil {
+		return 0, d.errorf("unexpected end of data")
+	}
+	d.off++
+	return c, nil
+}
+
+func (d *msgpackReader) bytes(n uint64) ([]byte, error) {
+	if d.limit >= 0 && n > uint64(d.limit-d.off) {
+		return nil, d.errorf("length %d exceeds the remaining data", n)
+	}
+
+	var buf bytes.Buffer
+	if n < 64*1024 {
+		buf.Grow(int(n))
+	}
+
+	read, err := io.CopyN(&buf, d.r, int64(n))
+	d.off += read
+	if err != nil {
+		return nil, d.errorf("unexpected end of data")
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (d *msgpackReader) uint(size int) (uint64, error) {
+	b, err := d.bytes(uint64(size))
+	if err != nil {
+		return 0, err
+	}
+
+	switch size {
+	case 1:
+		return uint64(b[0]), nil
+	case 2:
+		return uint64(binary.BigEndian.Uint16(b)), nil
+	case 4:
+		return uint64(binary.BigEndian.Uint32(b)), nil
+	}
+	return binary.BigEndian.Uint64(b), nil
+}
+
+// count checks a declared element count; every element takes at least one byte.
+
+func (d *msgpackReader) count(size int) (int, error) {
+	n, err := d.uint(size)
+	if err != nil {
+		return 0, err
+	}
+
+	if d.limit >= 0 && n > uint64(d.limit-d.off) {
+		return 0, d.errorf("count %d exceeds the remaining data", n)
+	}
+
+	return int(n), nil
+}
+
+func (d *msgpackReader) value() (interface{}, error) {
+	c, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0x80 && c <= 0x8f:
+		return d.mapping(int(c & 0x0f))
+	case c >= 0x90 && c <= 0x9f:
+		return d.array(int(c & 0x0f))
+	case c >= 0xa0 && c <= 0xbf:
+		b, err := d.bytes(uint64(c & 0x1f))
+		return string(b), err
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, nil
+	case 0xc2:
+		return false, nil
+	case 0xc3:
+		return true, nil
+	case 0xc4, 0xc5, 0xc6:
+		n, err := d.uint(1 << (c - 0xc4))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(n)
+		return encodeBytes(b), err
+	case 0xc7, 0xc8, 0xc9:
+		n, err := d.uint(1 << (c - 0xc7))
+		if err != nil {
+			return nil, err
+		}
+		return d.ext(n)
+	case 0xca:
+		u, err := d.uint(4)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(float64(math.Float32frombits(uint32(u))))
+	case 0xcb:
+		u, err := d.uint(8)
+		if err != nil {
+			return nil, err
+		}
+		return d.float(math.Float64frombits(u))
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, err := d.uint(1 << (c - 0xcc))
+		if err != nil {
+			return nil, err
+		}
+		if u > math.MaxInt64 {
+			return u, nil
+		}
+		return int64(u), nil
+	case 0xd0, 0xd1, 0xd2, 0xd3:
+		size := 1 << (c - 0xd0)
+		u, err := d.uint(size)
+		if err != nil {
+			return nil, err
+		}
+		shift := 64 - 8*size
+		return int64(u<<shift) >> shift, nil
+	case 0xd4, 0xd5, 0xd6, 0xd7, 0xd8:
+		return d.ext(1 << (c - 0xd4))
+	case 0xd9, 0xda, 0xdb:
+		n, err := d.uint(1 << (c - 0xd9))
+		if err != nil {
+			return nil, err
+		}
+		b, err := d.bytes(n)
+		return string(b), err
+	case 0xdc, 0xdd:
+		n, err := d.count(2 << (c - 0xdc))
+		if err != nil {
+			return nil, err
+		}
+		return d.array(n)
+	case 0xde, 0xdf:
+		n, err := d.count(2 << (c - 0xde))
+		if err != nil {
+			return nil, err
+		}
+		return d.mapping(n)
+	}
+
+	return nil, d.errorf("unknown type byte 0x%02x", c)
+}
+
+// float applies the NonFiniteFloatPolicy to NaN and ±Inf instead of letting
+// the document drop them.
+
+func (d *msgpackReader) float(f float64) (interface{}, error) {
+	if !isNonFinite(f) {
+		return f, nil
+	}
+
+	if v, keep, _ := nonFiniteValue(f, nonFinitePolicy); keep {
+		return v, nil
+	}
+	return nil, fmt.Errorf("%w at offset %d", ErrNonFiniteFloat, d.off)
+}
+
+func (d *msgpackReader) ext(n uint64) (interface{}, error) {
+	t, err := d.byte()
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := d.bytes(n)
+	if err != nil {
+		return nil, err
+	}
+
+	obj := d.newObject()
+	obj.Put("$ext", int64(int8(t)))
+	obj.Put("$data", base64.StdEncoding.EncodeToString(b))
+	return obj, nil
+}
+
+func (d *msgpackReader) newObject() *DO {
+	obj := NewDO()
+	if d.ordered {
+		obj.SetOrdered(true)
+	}
+	return obj
+}
+
+func (d *msgpackReader) enter() error {
+	d.depth++
+	if d.depth > msgpackMaxDepth {
+		return d.errorf("nesting deeper than %d", msgpackMaxDepth)
+	}
+	return nil
+}
+
+func (d *msgpackReader) array(n int) (*DA, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	arr := NewDA()
+	for i := 0; i < n; i++ {
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		arr.PutArray(v)
+	}
+
+	return arr, nil
+}
+
+func (d *msgpackReader) mapping(n int) (*DO, error) {
+	if err := d.enter(); err != nil {
+		return nil, err
+	}
+	defer func() { d.depth-- }()
+
+	obj := d.newObject()
+	for i := 0; i < n; i++ {
+		k, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		switch t := k.(type) {
+		case string:
+			key = t
+		case *DO, *DA:
+			return nil, d.errorf("map key must be a scalar")
+		default:
+			key = encodeToString(t, "")
+		}
+
+		v, err := d.value()
+		if err != nil {
+			return nil, err
+		}
+		obj.Put(key, v)
+	}
+
+	return obj, nil
+}
+
+func appendMsgpackUint(buf []byte, u uint64) []byte {
+	switch {
+	case u <= 0x7f:
+		return append(buf, byte(u))
+	case u <= math.MaxUint8:
+		return append(buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(u))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xcf), u)
+}
+
+func appendMsgpackInt(buf []byte, i int64) []byte {
+	switch {
+	case i >= 0:
+		return appendMsgpackUint(buf, uint64(i))
+	case i >= -32:
+		return append(buf, byte(i))
+	case i >= math.MinInt8:
+		return append(buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(i))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(i))
+}
+
+// appendMsgpackHeader writes the header of a str (0xa0), array (0x90) or
+// map (0x80) of n elements.
+
+func appendMsgpackHeader(buf []byte, fix byte, n int) []byte {
+	code16, code32 := byte(0xde), byte(0xdf)
+
+	switch fix {
+	case 0xa0:
+		if n < 32 {
+			return append(buf, fix|byte(n))
+		}
+		if n <= math.MaxUint8 {
+			return append(buf, 0xd9, byte(n))
+		}
+		code16, code32 = 0xda, 0xdb
+	case 0x90:
+		code16, code32 = 0xdc, 0xdd
+	}
+
+	switch {
+	case n < 16 && fix != 0xa0:
+		return append(buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, code16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, code32), uint32(n))
+}
+
+func appendMsgpackExt(buf []byte, obj *DO) ([]byte, bool) {
+	if obj.Len() != 2 {
+		return buf, false
+	}
+
+	t, ok := obj.Map["$ext"].(int64)
+	s, sok := obj.Map["$data"].(string)
+	if !ok || !sok || t < math.MinInt8 || t > math.MaxInt8 {
+		return buf, false
+	}
+
+	data, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return buf, false
+	}
+
+	switch n := len(data); {
+	case n == 1:
+		buf = append(buf, 0xd4)
+	case n == 2:
+		buf = append(buf, 0xd5)
+	case n == 4:
+		buf = append(buf, 0xd6)
+	case n == 8:
+		buf = append(buf, 0xd7)
+	case n == 16:
+		buf = append(buf, 0xd8)
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xc7, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xc8), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xc9), uint32(n))
+	}
+
+	buf = append(buf, byte(int8(t)))
+	return append(buf, data...), true
+}
+
+func appendMsgpack(buf []byte, v interface{}) ([]byte, error) {
+	switch t := v.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if t {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case string:
+		buf = appendMsgpackHeader(buf, 0xa0, len(t))
+		return append(buf, t...), nil
+	case int, int8, int16, int32, int64:
+		i, _ := getIntBase(t)
+		return appendMsgpackInt(buf, i), nil
+	case uint, uint8, uint16, uint32, uint64:
+		return appendMsgpackUint(buf, reflect.ValueOf(t).Uint()), nil
+	case float32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xca), math.Float32bits(t)), nil
+	case float64:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(t)), nil
+	case Number:
+		if i, err := t.Int64(); err == nil {
+			return appendMsgpackInt(buf, i), nil
+		}
+		if u, err := t.Uint64(); err == nil {
+			return appendMsgpackUint(buf, u), nil
+		}
+		f, _ := t.Float64()
+		return appendMsgpack(buf, f)
+	case DO:
+		return appendMsgpack(buf, &t)
+	case DA:
+		return appendMsgpack(buf, &t)
+	case *DO:
+		if ext, ok := appendMsgpackExt(buf, t); ok {
+			return ext, nil
+		}
+
+		keys := t.Keys()
+		buf = appendMsgpackHeader(buf, 0x80, len(keys))
+		for _, k := range keys {
+			buf = appendMsgpackHeader(buf, 0xa0, len(k))
+			buf = append(buf, k...)
+
+			var err error
+			if buf, err = appendMsgpack(buf, t.Map[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *DA:
+		buf = appendMsgpackHeader(buf, 0x90, t.Size())
+		for _, each := range t.Element {
+			var err error
+			if buf, err = appendMsgpack(buf, each); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case *JSON:
+		return appendMsgpack(buf, t.Interface())
+	}
+
+	return nil, fmt.Errorf("%w: %T", ErrUnsupportedMsgpack, v)
+}
@@ -0,0 +1,162 @@
+package djson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	jsonDoc := `{"bool":true,"empty":{},"float":1.5,"int":-129,"list":[0,127,128,-32,-33,65536,4294967296,-9223372036854775808],"long":"` +
+		`abcdefghijklmnopqrstuvwxyz0123456789","name":"Ricardo Longa","nested":{"한글":["값"]},"nil":null}`
+
+	mJson := New().Parse(jsonDoc)
+
+	data, err := mJson.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	back, err := New().ParseMsgpack(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := back.ToString(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	var buf bytes.Buffer
+	if n, err := mJson.WriteMsgpack(&buf); err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("Expected WriteMsgpack to write %d bytes, but got %d (%v)", len(data), n, err)
+	}
+}
+
+func TestMsgpackEncoding(t *testing.T) {
+	cases := map[string]string{
+		`{"a":1}`:           "81a16101",
+		`[1,-1,200,-200]`:   "9401ffccc8d1ff38",
+		`[true,false,null]`: "93c3c2c0",
+		`[0.5]`:             "91cb3fe0000000000000",
+	}
+
+	for doc, expected := range cases {
+		mJson, _ := New().ParseE(doc)
+		data, err := mJson.MarshalMsgpack()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result := hex.EncodeToString(data); result != expected {
+			t.Errorf("%s: Expected %s, but got %s", doc, expected, result)
+		}
+	}
+
+	if data, _ := NewString("abc").MarshalMsgpack(); hex.EncodeToString(data) != "a3616263" {
+		t.Errorf("Expected a3616263, but got %x", data)
+	}
+}
+
+func TestMsgpackDecodeTypes(t *testing.T) {
+	cases := map[string]string{
+		"cfffffffffffffffff":             `18446744073709551615`,
+		"ca3fc00000":                     `1.5`,
+		"c403010203":                     `AQID`,
+		"d9036b6579":                     `key`,
+		"82a162c0a161d3fffffffffffffffe": `{"a":-2,"b":null}`,
+		"8101a36f6e65":                   `{"1":"one"}`,
+		"d6ff00000001":                   `{"$data":"AAAAAQ==","$ext":-1}`,
+		"dc0002c3c2":                     `[true,false]`,
+	}
+
+	for input, expected := range cases {
+		data, _ := hex.DecodeString(input)
+		mJson, err := New().ParseMsgpack(data)
+		if err != nil {
+			t.Errorf("%s: %v", input, err)
+			continue
+		}
+
+		if result := mJson.ToString(); result != expected {
+			t.Errorf("%s: Expected %s, but got %s", input, expected, result)
+		}
+	}
+
+	data, _ := hex.DecodeString("d6ff00000001")
+	mJson, _ := New().ParseMsgpack(data)
+	if back, _ := mJson.MarshalMsgpack(); !bytes.Equal(back, data) {
+		t.Errorf("Expected ext to round trip, but got %x", back)
+	}
+}
+
+func TestMsgpackErrors(t *testing.T) {
+	inputs := []string{
+		"",
+		"92c3",
+		"a5616263",
+		"dbffffffff",
+		"ddffffffff",
+		"c1",
+		"c3c3",
+	}
+
+	for _, input := range inputs {
+		data, _ := hex.DecodeString(input)
+		if _, err := New().ParseMsgpack(data); !errors.Is(err, ErrInvalidMsgpack) {
+			t.Errorf("%s: Expected ErrInvalidMsgpack, but got %v", input, err)
+		}
+	}
+}
+
+func TestMsgpackNonFinite(t *testing.T) {
+	inputs := []string{"cb7ff8000000000000", "ca7f800000", "91cbfff0000000000000", "81a161ca7fc00000"}
+
+	for _, input := range inputs {
+		data, _ := hex.DecodeString(input)
+		if _, err := New().ParseMsgpack(data); !errors.Is(err, ErrNonFiniteFloat) {
+			t.Errorf("%s: Expected ErrNonFiniteFloat, but got %v", input, err)
+		}
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteString)
+	defer SetNonFiniteFloatPolicy(NonFiniteDrop)
+
+	data, _ := hex.DecodeString("92ca7f800000cbfff0000000000000")
+	mJson, err := New().ParseMsgpack(data)
+	if err != nil || mJson.ToString() != `["Infinity","-Infinity"]` {
+		t.Errorf("Expected the policy strings, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteNull)
+	data, _ = hex.DecodeString("81a161cb7ff8000000000000")
+	mJson, err = New().ParseMsgpack(data)
+	if err != nil || mJson.ToString() != `{"a":null}` {
+		t.Errorf("Expected null, but got %s (%v)", mJson.ToString(), err)
+	}
+}
+
+func TestMsgpackDecoder(t *testing.T) {
+	var buf bytes.Buffer
+	for _, mJson := range []*JSON{New().Parse(`{"id":1}`), New().Parse(`[1,2]`), NewString("x")} {
+		mJson.WriteMsgpack(&buf)
+	}
+
+	dec := NewMsgpackDecoder(&buf)
+
+	expected := []string{`{"id":1}`, `[1,2]`, `x`}
+	for i := range expected {
+		mJson, err := dec.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result := mJson.ToString(); result != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], result)
+		}
+	}
+
+	if _, err := dec.Next(); err != io.EOF {
+		t.Errorf("Expected io.EOF, but got %v", err)
+	}
+}
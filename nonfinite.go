@@ -0,0 +1,165 @@
+package djson
+
+import (
+	"errors"
+	"math"
+
+	"github.com/volatiletech/null/v8"
+)
+
+// NonFiniteFloatPolicy decides what happens to NaN and ±Inf, which have no
+// JSON representation.
+type NonFiniteFloatPolicy int
+
+const (
+	NonFiniteDrop   NonFiniteFloatPolicy = iota // leave the value out
+	NonFiniteError                              // report ErrNonFiniteFloat
+	NonFiniteNull                               // store null
+	NonFiniteString                             // store "NaN", "Infinity" or "-Infinity"
+)
+
+var ErrNonFiniteFloat = errors.New("djson: non-finite float")
+
+// nonFiniteValue applies policy to f. keep is false when the value must be
+// left out.
+
+func nonFiniteValue(f float64, policy NonFiniteFloatPolicy) (v interface{}, keep bool, err error) {
+	switch policy {
+	case NonFiniteError:
+		return nil, false, ErrNonFiniteFloat
+	case NonFiniteNull:
+		return nil, true, nil
+	case NonFiniteString:
+		switch {
+		case math.IsNaN(f):
+			return "NaN", true, nil
+		case f > 0:
+			return "Infinity", true, nil
+		}
+		return "-Infinity", true, nil
+	}
+
+	return nil, false, nil
+}
+
+// nonFinitePolicy is how Put, ReplaceAt and Insert store NaN and ±Inf.
+var nonFinitePolicy = NonFiniteDrop
+
+// SetNonFiniteFloatPolicy sets how NaN and ±Inf floats put afterwards are
+// stored. Under NonFiniteError only the E variants (PutE, PutArrayE,
+// ReplaceAtE, InsertE and UpdatePathE) report ErrNonFiniteFloat and change
+// nothing; Put and the other plain mutators still leave the value out
+// silently, as with NonFiniteDrop.
+
+func SetNonFiniteFloatPolicy(policy NonFiniteFloatPolicy) {
+	nonFinitePolicy = policy
+}
+
+func isNonFinite(f float64) bool {
+	return math.IsNaN(f) || math.IsInf(f, 0)
+}
+
+// storedFloat returns what the mutators store for a float value. keep is
+// false when the value must be left out.
+
+func storedFloat(value interface{}) (interface{}, bool) {
+	var f float64
+	switch t := value.(type) {
+	case float32:
+		f = float64(t)
+	case float64:
+		f = t
+	}
+
+	if !math.IsNaN(f) && !math.IsInf(f, 0) {
+		return value, true
+	}
+
+	v, keep, _ := nonFiniteValue(f, nonFinitePolicy)
+	return v, keep
+}
+
+// checkValue reports what the E variants refuse to store: ErrInvalidNumber
+// for a Number that is not valid JSON number text, and ErrNonFiniteFloat for
+// NaN or ±Inf when the policy is NonFiniteError, anywhere in value.
+
+func checkValue(value interface{}) error {
+	if n, ok := toNumber(value); ok {
+		if !isNumberLexeme(string(n)) {
+			return ErrInvalidNumber
+		}
+		return nil
+	}
+
+	var f float64
+	switch t := value.(type) {
+	case float32:
+		f = float64(t)
+	case float64:
+		f = t
+	case null.Float32:
+		f = float64(t.Float32)
+	case null.Float64:
+		f = t.Float64
+	case *float32:
+		if t != nil {
+			f = float64(*t)
+		}
+	case *float64:
+		if t != nil {
+			f = *t
+		}
+	case []float32:
+		return checkSlice(t)
+	case []float64:
+		return checkSlice(t)
+	case []null.Float32:
+		return checkSlice(t)
+	case []null.Float64:
+		return checkSlice(t)
+	case []*float32:
+		return checkSlice(t)
+	case []*float64:
+		return checkSlice(t)
+	case []Number:
+		return checkSlice(t)
+	case []interface{}:
+		return checkSlice(t)
+	case Array:
+		return checkSlice(t)
+	case map[string]interface{}:
+		for _, each := range t {
+			if err := checkValue(each); err != nil {
+				return err
+			}
+		}
+	case Object:
+		return checkValue(map[string]interface{}(t))
+	case *DO: // lazy subtrees were parsed, so they hold no such values
+		if t != nil && t.lazy == nil {
+			return checkValue(t.Map)
+		}
+	case *DA:
+		if t != nil && t.lazy == nil {
+			return checkSlice(t.Element)
+		}
+	case *JSON:
+		if t != nil {
+			return checkValue(t.Interface())
+		}
+	}
+
+	if nonFinitePolicy == NonFiniteError && (math.IsNaN(f) || math.IsInf(f, 0)) {
+		return ErrNonFiniteFloat
+	}
+	return nil
+}
+
+func checkSlice[T any](s []T) error {
+	for idx := range s {
+		if err := checkValue(s[idx]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package djson
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/volatiletech/null/v8"
+)
+
+func TestNonFiniteFloatPolicy(t *testing.T) {
+	defer SetNonFiniteFloatPolicy(NonFiniteDrop)
+
+	put := func() *JSON {
+		mJson := New().Put(Object{"ok": 1.5, "nan": math.NaN()})
+		mJson.Put("inf", math.Inf(-1))
+		mJson.Put("nf", null.Float64From(math.Inf(1)))
+		mJson.Put("list", []float64{1, math.NaN()})
+		mJson.UpdatePath(`[list][0]`, math.Inf(1))
+		return mJson
+	}
+
+	tests := []struct {
+		policy   NonFiniteFloatPolicy
+		expected string
+	}{
+		{NonFiniteDrop, `{"list":[1],"ok":1.5}`},
+		{NonFiniteError, `{"list":[1],"ok":1.5}`},
+		{NonFiniteNull, `{"inf":null,"list":[null,null],"nan":null,"nf":null,"ok":1.5}`},
+		{NonFiniteString, `{"inf":"-Infinity","list":["Infinity","NaN"],"nan":"NaN","nf":"Infinity","ok":1.5}`},
+	}
+
+	for _, test := range tests {
+		SetNonFiniteFloatPolicy(test.policy)
+		if result := put().ToString(); result != test.expected {
+			t.Errorf("policy %d: Expected %s, but got %s", test.policy, test.expected, result)
+		}
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteNull)
+	if result := New().Put(math.NaN()).ToString(); result != "null" {
+		t.Errorf("Expected a NULL document, but got %s", result)
+	}
+}
+
+func TestNonFiniteFloatErrors(t *testing.T) {
+	SetNonFiniteFloatPolicy(NonFiniteError)
+	defer SetNonFiniteFloatPolicy(NonFiniteDrop)
+
+	mJson := New().Put(Object{"a": 1, "list": Array{1, 2}})
+
+	errs := []error{
+		mJson.PutE("b", math.NaN()),
+		mJson.PutE(Object{"c": Object{"d": []float32{float32(math.Inf(1))}}}),
+		mJson._Object.PutE("e", null.Float64From(math.NaN())),
+		mJson._Object.PutE("f", map[string]interface{}{"g": Array{math.Inf(-1)}}),
+	}
+
+	nan := math.NaN()
+	nested := New().Put(Array{1})
+	nested._Array.Element[0] = math.Inf(1)
+	errs = append(errs,
+		mJson.PutE("h", []*float64{nil, &nan}),
+		mJson.PutE("i", nested),
+		mJson.PutE("j", Object{"k": nested._Array}),
+		New().PutArrayE(1, math.NaN()),
+	)
+
+	arr, _ := mJson._Object.Array("list")
+	errs = append(errs, arr.ReplaceAtE(0, math.NaN()), arr.InsertE(0, math.Inf(1)), arr.PutArrayE(null.Float32From(float32(nan))))
+
+	_, err := mJson.UpdatePathE(`[list][1]`, math.NaN())
+	errs = append(errs, err)
+
+	for idx, err := range errs {
+		if !errors.Is(err, ErrNonFiniteFloat) {
+			t.Errorf("%d: Expected ErrNonFiniteFloat, but got %v", idx, err)
+		}
+	}
+
+	if result := mJson.ToString(); result != `{"a":1,"list":[1,2]}` {
+		t.Errorf("Expected the document to be unchanged, but got %s", result)
+	}
+
+	if err := mJson.PutE("b", 2.5); err != nil || mJson.Float("b") != 2.5 {
+		t.Errorf("Expected a finite float to be put, but got %v", err)
+	}
+}
+
+func TestInsertDropsNullFloat(t *testing.T) {
+	mJson := New().PutArray(1)
+	mJson.PutArray(null.Float64From(math.NaN()), null.Float32From(float32(math.Inf(1))))
+	mJson.PutArray(null.Float64{}, null.Float64From(2))
+
+	if result := mJson.ToString(); result != `[1,0,2]` {
+		t.Errorf("Expected [1,0,2], but got %s", result)
+	}
+}
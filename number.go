@@ -0,0 +1,311 @@
+package djson
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Number keeps the exact text of a JSON number, so IDs beyond 64 bits and
+// long decimals survive a parse/serialize round trip unchanged.
+// Numbers are produced by parsing in lossless mode (see SetLosslessNumber)
+// or by putting a Number, *big.Int or *big.Float.
+type Number string
+
+var ErrNumberRange = errors.New("djson: number out of range")
+
+var losslessNumber bool
+
+// SetLosslessNumber makes every parse afterwards keep numbers as Number.
+
+func SetLosslessNumber(lossless bool) {
+	losslessNumber = lossless
+}
+
+func (n Number) String() string {
+	return string(n)
+}
+
+// IsInt reports whether the lexeme is written without fraction or exponent.
+
+func (n Number) IsInt() bool {
+	return !strings.ContainsAny(string(n), ".eE")
+}
+
+func (n Number) Int64() (int64, error) {
+	if n.IsInt() {
+		return strconv.ParseInt(string(n), 10, 64)
+	}
+
+	r, ok := n.Rat()
+	if !ok || !r.IsInt() || !r.Num().IsInt64() {
+		return 0, ErrNumberRange
+	}
+
+	return r.Num().Int64(), nil
+}
+
+func (n Number) Uint64() (uint64, error) {
+	if n.IsInt() {
+		return strconv.ParseUint(string(n), 10, 64)
+	}
+
+	r, ok := n.Rat()
+	if !ok || !r.IsInt() || !r.Num().IsUint64() {
+		return 0, ErrNumberRange
+	}
+
+	return r.Num().Uint64(), nil
+}
+
+func (n Number) Float64() (float64, error) {
+	return strconv.ParseFloat(string(n), 64)
+}
+
+// BigInt returns the value when it is integral, e.g. "12" or "1.2e1".
+
+func (n Number) BigInt() (*big.Int, bool) {
+	if n.IsInt() {
+		return new(big.Int).SetString(string(n), 10)
+	}
+
+	r, ok := n.Rat()
+	if !ok || !r.IsInt() {
+		return nil, false
+	}
+
+	return new(big.Int).Set(r.Num()), true
+}
+
+// Rat returns the exact decimal value.
+
+func (n Number) Rat() (*big.Rat, bool) {
+	return new(big.Rat).SetString(string(n))
+}
+
+func equalNumber(a, b Number) bool {
+	if a == b {
+		return true
+	}
+
+	ar, aok := a.Rat()
+	br, bok := b.Rat()
+
+	return aok && bok && ar.Cmp(br) == 0
+}
+
+// ErrInvalidNumber is reported by the E variants of Put for a Number that is
+// not valid JSON number text. The plain mutators leave such a value out.
+var ErrInvalidNumber = errors.New("djson: invalid number")
+
+func isNumberLexeme(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	p := newParser([]byte(s))
+	_, err := p.parseNumber()
+
+	return err == nil && p.pos == len(s)
+}
+
+func toNumber(v interface{}) (Number, bool) {
+	switch t := v.(type) {
+	case Number:
+		return t, true
+	case *big.Int:
+		if t != nil {
+			return Number(t.String()), true
+		}
+	case big.Int:
+		return Number(t.String()), true
+	case *big.Float:
+		if t != nil && !t.IsInf() {
+			return Number(t.Text('g', -1)), true
+		}
+	case big.Float:
+		if !t.IsInf() {
+			return Number(t.Text('g', -1)), true
+		}
+	}
+
+	return "", false
+}
+
+func getBigIntBase(v interface{}) (*big.Int, bool) {
+	switch t := v.(type) {
+	case Number:
+		return t.BigInt()
+	case int, int8, int16, int32, int64:
+		i, _ := getIntBase(t)
+		return big.NewInt(i), true
+	case uint, uint8, uint16, uint32, uint64:
+		return new(big.Int).SetUint64(reflect.ValueOf(t).Uint()), true
+	case float32, float64:
+		f, _ := getFloatBase(t)
+		bf := big.NewFloat(f)
+		if !bf.IsInt() {
+			return nil, false
+		}
+		i, _ := bf.Int(nil)
+		return i, true
+	}
+
+	return nil, false
+}
+
+func getDecimalBase(v interface{}) (*big.Rat, bool) {
+	switch t := v.(type) {
+	case Number:
+		return t.Rat()
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		i, _ := getBigIntBase(t)
+		return new(big.Rat).SetInt(i), true
+	case float32:
+		return new(big.Rat).SetString(strconv.FormatFloat(float64(t), 'g', -1, 32))
+	case float64:
+		return new(big.Rat).SetString(strconv.FormatFloat(t, 'g', -1, 64))
+	}
+
+	return nil, false
+}
+
+func getNumberStringBase(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case Number:
+		return string(t), true
+	case int, int8, int16, int32, int64:
+		i, _ := getIntBase(t)
+		return strconv.FormatInt(i, 10), true
+	case uint, uint8, uint16, uint32, uint64:
+		return strconv.FormatUint(reflect.ValueOf(t).Uint(), 10), true
+	case float32:
+		return string(appendFloat(nil, float64(t), 32)), true
+	case float64:
+		return string(appendFloat(nil, t, 64)), true
+	}
+
+	return "", false
+}
+
+func (m *DO) BigInt(key string) (*big.Int, bool) {
+	m.load()
+
+	value, ok := m.Map[key]
+	if !ok {
+		return nil, false
+	}
+
+	return getBigIntBase(value)
+}
+
+func (m *DO) Decimal(key string) (*big.Rat, bool) {
+	m.load()
+
+	value, ok := m.Map[key]
+	if !ok {
+		return nil, false
+	}
+
+	return getDecimalBase(value)
+}
+
+func (m *DO) NumberString(key string) (string, bool) {
+	m.load()
+
+	value, ok := m.Map[key]
+	if !ok {
+		return "", false
+	}
+
+	return getNumberStringBase(value)
+}
+
+func (m *DA) BigInt(idx int) (*big.Int, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return nil, false
+	}
+
+	return getBigIntBase(m.Element[idx])
+}
+
+func (m *DA) Decimal(idx int) (*big.Rat, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return nil, false
+	}
+
+	return getDecimalBase(m.Element[idx])
+}
+
+func (m *DA) NumberString(idx int) (string, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return "", false
+	}
+
+	return getNumberStringBase(m.Element[idx])
+}
+
+// BigInt returns nil when the value is missing or not an integral number.
+
+func (m *JSON) BigInt(key ...interface{}) *big.Int {
+	if v, ok := getBigIntBase(m.Interface(key...)); ok {
+		return v
+	}
+
+	return nil
+}
+
+// Decimal returns nil when the value is missing or not a number.
+
+func (m *JSON) Decimal(key ...interface{}) *big.Rat {
+	if v, ok := getDecimalBase(m.Interface(key...)); ok {
+		return v
+	}
+
+	return nil
+}
+
+// NumberString returns the number as written, or "" when the value is not a number.
+
+func (m *JSON) NumberString(key ...interface{}) string {
+	if v, ok := getNumberStringBase(m.Interface(key...)); ok {
+		return v
+	}
+
+	return ""
+}
+
+// SetLosslessNumber makes numbers parsed into this document keep their exact text.
+
+func (m *JSON) SetLosslessNumber(lossless bool) *JSON {
+	m._Lossless = lossless
+	return m
+}
+
+func (m *JSON) setNumber(n Number) {
+	m._Number = n
+	m._Array = nil
+	m._Object = nil
+
+	m._Unsigned = false
+
+	if n.IsInt() {
+		i, err := n.Int64()
+		if u, uerr := strconv.ParseUint(string(n), 10, 64); err != nil && uerr == nil {
+			i, m._Unsigned = int64(u), true
+		}
+		m._Int = i
+		m._Type = INT
+	} else {
+		m._Float, _ = n.Float64()
+		m._Type = FLOAT
+	}
+}
@@ -0,0 +1,129 @@
+package djson
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+)
+
+func TestLosslessNumberRoundTrip(t *testing.T) {
+	jsonDoc := `{"amount":0.1000000000000000055,"id":18446744073709551616,"list":[1e400,-0.0,12],"one":1.0}`
+
+	mJson := New().SetLosslessNumber(true).Parse(jsonDoc)
+	if mJson.HasKey("id") == false {
+		t.Fatalf("Expected parsed object, but got %s", mJson.ToString())
+	}
+
+	if result := mJson.ToString(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	var buf bytes.Buffer
+	mJson.WriteTo(&buf)
+	if result := buf.String(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	if result := mJson.Clone().ToString(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	if !mJson.Equal(New().SetLosslessNumber(true).Parse(`{"amount":0.10000000000000000550,"id":18446744073709551616,"list":[1e400,0,12.0],"one":1}`)) {
+		t.Errorf("Expected numerically equal documents")
+	}
+
+	if result := New().Parse(jsonDoc).NumberString("id"); result == "18446744073709551616" {
+		t.Errorf("Expected default mode to round the id, but got %s", result)
+	}
+}
+
+func TestLosslessNumberAccessors(t *testing.T) {
+	mJson := New().SetLosslessNumber(true).Parse(`{"id":18446744073709551616,"amount":12.50,"small":42,"name":"x"}`)
+
+	expected, _ := new(big.Int).SetString("18446744073709551616", 10)
+	if result := mJson.BigInt("id"); result == nil || result.Cmp(expected) != 0 {
+		t.Errorf("Expected %s, but got %v", expected, result)
+	}
+
+	if result := mJson.Decimal("amount"); result == nil || result.Cmp(big.NewRat(25, 2)) != 0 {
+		t.Errorf("Expected 25/2, but got %v", result)
+	}
+
+	if result := mJson.NumberString("amount"); result != "12.50" {
+		t.Errorf("Expected 12.50, but got %s", result)
+	}
+
+	if result := mJson.Int("small"); result != 42 {
+		t.Errorf("Expected 42, but got %d", result)
+	}
+
+	if result := mJson.Float("amount"); result != 12.5 {
+		t.Errorf("Expected 12.5, but got %f", result)
+	}
+
+	if result := mJson.Type("amount"); result != "float" {
+		t.Errorf("Expected float, but got %s", result)
+	}
+
+	if mJson.BigInt("amount") != nil || mJson.BigInt("name") != nil || mJson.NumberString("none") != "" {
+		t.Errorf("Expected nil for non-integral or missing values")
+	}
+
+	if result := NewInt(7).BigInt(); result == nil || result.Int64() != 7 {
+		t.Errorf("Expected 7, but got %v", result)
+	}
+}
+
+func TestPutBigNumber(t *testing.T) {
+	huge, _ := new(big.Int).SetString("-123456789012345678901234567890", 10)
+
+	mJson := New().Put(Object{
+		"big": huge,
+		"dec": Number("3.14159265358979323846264338327950288"),
+	})
+
+	expected := `{"big":-123456789012345678901234567890,"dec":3.14159265358979323846264338327950288}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result := New().Put(huge).ToString(); result != huge.String() {
+		t.Errorf("Expected %s, but got %s", huge, result)
+	}
+
+	if result := New().SetLosslessNumber(true).Parse("  100000000000000000000001 ").ToString(); result != "100000000000000000000001" {
+		t.Errorf("Expected 100000000000000000000001, but got %s", result)
+	}
+
+	dec := NewDecoder(strings.NewReader(`{"n":1.10}`))
+	dec.Lossless = true
+	if record, err := dec.Next(); err != nil || record.NumberString("n") != "1.10" {
+		t.Errorf("Expected 1.10, but got %v (%v)", record, err)
+	}
+}
+
+func TestPutInvalidNumber(t *testing.T) {
+	mJson := New().Put(Object{"n": Number("abc"), "ok": Number("1.5")})
+	mJson.PutObject("list", Array{Number("1"), Number("1."), Number("2")})
+
+	expected := `{"list":[1,2],"ok":1.5}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result := New().Put(Number("0x10")).ToString(); result != "null" {
+		t.Errorf("Expected null, but got %s", result)
+	}
+
+	if err := mJson.PutE("n", Number("--1")); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("Expected ErrInvalidNumber, but got %v", err)
+	}
+	if err := mJson.PutE(Object{"m": Array{Number("NaN")}}); !errors.Is(err, ErrInvalidNumber) {
+		t.Errorf("Expected ErrInvalidNumber, but got %v", err)
+	}
+	if mJson.HasKey("n") || mJson.HasKey("m") {
+		t.Errorf("Expected nothing to be stored, but got %s", mJson.ToString())
+	}
+}
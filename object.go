@@ -1,35 +1,174 @@
 package djson
 
 import (
-	"math"
 	"reflect"
+	"sort"
+	"time"
 
 	"github.com/goccy/go-json"
 	"github.com/volatiletech/null/v8"
 )
 
 type DO struct {
-	Map map[string]interface{}
+	Map      map[string]interface{}
+	ordered  bool
+	keys     []string
+	lazy     *lazySource
+	loadErr  error
+	coercion Coercion
+}
+
+var orderedObject bool
+
+// SetOrderedObject makes every DO created afterwards keep insertion order.
+
+func SetOrderedObject(ordered bool) {
+	orderedObject = ordered
 }
 
 func NewDO() *DO {
 	return &DO{
-		Map: make(map[string]interface{}),
+		Map:     make(map[string]interface{}),
+		ordered: orderedObject,
+	}
+}
+
+func NewOrderedDO() *DO {
+	return NewDO().SetOrdered(true)
+}
+
+// SetOrdered switches insertion-order tracking on or off for this object.
+// Keys already present are ordered by name when tracking is switched on.
+
+func (m *DO) SetOrdered(ordered bool) *DO {
+	m.load()
+
+	if ordered && !m.ordered {
+		m.keys = m.sortedKeys()
+	}
+
+	if !ordered {
+		m.keys = nil
+	}
+
+	m.ordered = ordered
+	return m
+}
+
+func (m *DO) setOrderedDeep(ordered bool) {
+	m.SetOrdered(ordered)
+
+	for _, v := range m.Map {
+		switch t := v.(type) {
+		case *DO:
+			t.setOrderedDeep(ordered)
+		case *DA:
+			t.setOrderedDeep(ordered)
+		}
+	}
+}
+
+func (m *DO) IsOrdered() bool {
+	return m.ordered
+}
+
+// Keys returns the keys in insertion order for an ordered object and
+// sorted by name otherwise.
+
+func (m *DO) Keys() []string {
+	m.load()
+
+	if !m.ordered {
+		return m.sortedKeys()
 	}
+
+	return append([]string{}, m.orderedKeys()...)
+}
+
+func (m *DO) sortedKeys() []string {
+	m.load()
+
+	keys := make([]string, 0, len(m.Map))
+	for k := range m.Map {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// orderedKeys reconciles the key list with Map, which callers may have
+// modified directly, and returns it without copying.
+
+func (m *DO) orderedKeys() []string {
+	m.load()
+
+	inSync := len(m.keys) == len(m.Map)
+	for idx := 0; inSync && idx < len(m.keys); idx++ {
+		_, inSync = m.Map[m.keys[idx]]
+	}
+
+	if inSync {
+		return m.keys
+	}
+
+	seen := make(map[string]bool, len(m.Map))
+	keys := make([]string, 0, len(m.Map))
+	for _, k := range m.keys {
+		if _, ok := m.Map[k]; ok && !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+
+	missing := make([]string, 0)
+	for k := range m.Map {
+		if !seen[k] {
+			missing = append(missing, k)
+		}
+	}
+	sort.Strings(missing)
+
+	m.keys = append(keys, missing...)
+	return m.keys
 }
 
 func (m *DO) Put(key string, value interface{}) *DO {
+	m.load()
+
+	_, exists := m.Map[key]
+
+	m.put(key, value)
+
+	if m.coercion != CoercionDefault {
+		setCoercionOf(m.Map[key], m.coercion)
+	}
+
+	if m.ordered && !exists {
+		if _, ok := m.Map[key]; ok {
+			m.keys = append(m.keys, key)
+		}
+	}
+
+	return m
+}
+
+// PutE works like Put but reports ErrNonFiniteFloat under NonFiniteError
+// and ErrInvalidNumber, leaving m unchanged.
+
+func (m *DO) PutE(key string, value interface{}) error {
+	if err := checkValue(value); err != nil {
+		return err
+	}
+
+	m.Put(key, value)
+	return nil
+}
 
+func (m *DO) put(key string, value interface{}) *DO {
 	if IsFloatType(value) {
-		switch t := value.(type) {
-		case float32:
-			if !math.IsNaN(float64(t)) && !math.IsInf(float64(t), 0) {
-				m.Map[key] = t
-			}
-		case float64:
-			if !math.IsNaN(t) && !math.IsInf(float64(t), 0) {
-				m.Map[key] = t
-			}
+		if v, keep := storedFloat(value); keep {
+			m.Map[key] = v
 		}
 
 		return m
@@ -40,6 +179,13 @@ func (m *DO) Put(key string, value interface{}) *DO {
 		return m
 	}
 
+	if n, ok := toNumber(value); ok {
+		if isNumberLexeme(string(n)) {
+			m.Map[key] = n
+		}
+		return m
+	}
+
 	if n, ok := value.(json.Number); ok {
 		if i, err := n.Int64(); err == nil {
 			m.Map[key] = i
@@ -59,6 +205,26 @@ func (m *DO) Put(key string, value interface{}) *DO {
 		} else {
 			m.Map[key] = ""
 		}
+	case time.Time:
+		m.Map[key] = encodeTime(t)
+	case *time.Time:
+		if t != nil {
+			m.Map[key] = encodeTime(*t)
+		} else {
+			m.Map[key] = nil
+		}
+	case null.Time:
+		if t.Valid {
+			m.Map[key] = encodeTime(t.Time)
+		} else {
+			m.Map[key] = nil
+		}
+	case null.Bytes:
+		if t.Valid {
+			m.Map[key] = encodeBytes(t.Bytes)
+		} else {
+			m.Map[key] = ""
+		}
 	case null.Bool:
 		if t.Valid {
 			m.Map[key] = t.Bool
@@ -127,13 +293,17 @@ func (m *DO) Put(key string, value interface{}) *DO {
 		}
 	case null.Float32:
 		if t.Valid {
-			m.Map[key] = t.Float32
+			if v, keep := storedFloat(t.Float32); keep {
+				m.Map[key] = v
+			}
 		} else {
 			m.Map[key] = float32(0.0)
 		}
 	case null.Float64:
 		if t.Valid {
-			m.Map[key] = t.Float64
+			if v, keep := storedFloat(t.Float64); keep {
+				m.Map[key] = v
+			}
 		} else {
 			m.Map[key] = float64(0.0)
 		}
@@ -146,17 +316,19 @@ func (m *DO) Put(key string, value interface{}) *DO {
 	case *DA:
 		m.Map[key] = t
 	case map[string]interface{}:
-		m.Map[key] = MapToObject(t)
+		m.Map[key] = mapToObject(t, m.ordered)
 	case []interface{}:
 		m.Map[key] = SliceToArray(t)
 	case Object:
-		m.Map[key] = MapToObject(t)
+		m.Map[key] = mapToObject(t, m.ordered)
 	case Array:
 		m.Map[key] = SliceToArray(t)
 	case JSON:
 		m.Map[key] = t.Interface()
 	case *JSON:
 		m.Map[key] = t.Interface()
+	case NullJSON:
+		m.Map[key] = t.Interface()
 	case []string:
 		m.Map[key] = PremitiveSliceToArray(t)
 	case []bool:
@@ -177,8 +349,8 @@ func (m *DO) Put(key string, value interface{}) *DO {
 		m.Map[key] = PremitiveSliceToArray(t)
 	case []uint:
 		m.Map[key] = PremitiveSliceToArray(t)
-	case []uint8:
-		m.Map[key] = PremitiveSliceToArray(t)
+	case []byte:
+		m.Map[key] = encodeBytes(t)
 	case []uint16:
 		m.Map[key] = PremitiveSliceToArray(t)
 	case []uint32:
@@ -228,19 +400,23 @@ func (m *DO) PutArray(key string, array ...interface{}) *DO {
 }
 
 func (m *DO) Append(obj map[string]interface{}) *DO {
-	for k, v := range obj {
-		m.Put(k, v)
+	for _, k := range sortedMapKeys(obj) {
+		m.Put(k, obj[k])
 	}
 
 	return m
 }
 
 func (m *DO) HasKey(key string) bool {
+	m.load()
+
 	_, ok := m.Map[key]
 	return ok
 }
 
 func (m *DO) String(key string) string {
+	m.load()
+
 	if key == "" {
 		return ""
 	}
@@ -250,6 +426,11 @@ func (m *DO) String(key string) string {
 		return ""
 	}
 
+	if m.coercion != CoercionDefault {
+		s, _ := coerceString(value, m.coercion)
+		return s
+	}
+
 	switch t := value.(type) {
 	case DO:
 		return t.ToString()
@@ -271,6 +452,8 @@ func (m *DO) String(key string) string {
 }
 
 func (m *DO) String2(key string) (string, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return "", false
@@ -293,21 +476,25 @@ func (m *DO) String2(key string) (string, bool) {
 }
 
 func (m *DO) Get(key string) (interface{}, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return nil, false
 	}
 
-	return value, true
+	return loaded(value), true
 }
 
 func (m *DO) Type(key string) (string, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return "", false
 	}
 
-	switch value.(type) {
+	switch t := value.(type) {
 	case DA, *DA:
 		return "array", true
 	case DO, *DO:
@@ -316,6 +503,11 @@ func (m *DO) Type(key string) (string, bool) {
 		return "int", true
 	case float32, float64:
 		return "float", true
+	case Number:
+		if t.IsInt() {
+			return "int", true
+		}
+		return "float", true
 	case string:
 		return "string", true
 	case bool:
@@ -328,11 +520,17 @@ func (m *DO) Type(key string) (string, bool) {
 }
 
 func (m *DO) Bool(key string) (bool, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return false, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceBool(value, m.coercion)
+	}
+
 	if boolVal, ok := getBoolBase(value); ok {
 		return boolVal, true
 	}
@@ -341,11 +539,17 @@ func (m *DO) Bool(key string) (bool, bool) {
 }
 
 func (m *DO) Float(key string) (float64, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return 0, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceFloat(value, m.coercion)
+	}
+
 	if floatVal, ok := getFloatBase(value); ok {
 		return floatVal, true
 	}
@@ -354,11 +558,17 @@ func (m *DO) Float(key string) (float64, bool) {
 }
 
 func (m *DO) Int(key string) (int64, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return 0, false
 	}
 
+	if m.coercion != CoercionDefault {
+		return coerceInt(value, m.coercion)
+	}
+
 	if intVal, ok := getIntBase(value); ok {
 		return intVal, true
 	}
@@ -367,6 +577,8 @@ func (m *DO) Int(key string) (int64, bool) {
 }
 
 func (m *DO) Object(key string) (*DO, bool) {
+	m.load()
+
 	value, ok := m.Map[key]
 	if !ok {
 		return nil, false
@@ -376,8 +588,10 @@ func (m *DO) Object(key string) (*DO, bool) {
 	case DO:
 		return &t, true
 	case *DO:
+		loaded(t)
 		return t, true
 	case **DO:
+		loaded(*t)
 		return *t, true
 	}
 
@@ -385,6 +599,7 @@ func (m *DO) Object(key string) (*DO, bool) {
 }
 
 func (m *DO) Array(key string) (*DA, bool) {
+	m.load()
 
 	value, ok := m.Map[key]
 	if !ok {
@@ -395,8 +610,10 @@ func (m *DO) Array(key string) (*DA, bool) {
 	case DA:
 		return &t, true
 	case *DA:
+		loaded(t)
 		return t, true
 	case **DA:
+		loaded(*t)
 		return *t, true
 	}
 
@@ -405,35 +622,50 @@ func (m *DO) Array(key string) (*DA, bool) {
 }
 
 func (m *DO) Remove(keys ...string) *DO {
+	m.load()
+
 	for idx := range keys {
+		if _, ok := m.Map[keys[idx]]; !ok {
+			continue
+		}
+
 		delete(m.Map, keys[idx])
+
+		if m.ordered {
+			for i, k := range m.keys {
+				if k == keys[idx] {
+					m.keys = append(m.keys[:i], m.keys[i+1:]...)
+					break
+				}
+			}
+		}
 	}
 	return m
 }
 
 func (m *DO) ToStringPretty() string {
-	jsonByte, _ := json.MarshalIndent(ObjectToMap(m), "", "   ")
-	return string(jsonByte)
+	return encodeToString(m, "   ")
 }
 
 func (m *DO) ToString() string {
-	jsonByte, err := json.Marshal(ObjectToMap(m))
-	if err != nil {
-		// log.Println(err)
-		return ""
-	}
-	return string(jsonByte)
+	return encodeToString(m, "")
 }
 
 func (m *DO) Len() int {
+	m.load()
+
 	return len(m.Map)
 }
 
 func (m *DO) Size() int {
+	m.load()
+
 	return len(m.Map)
 }
 
 func (m *DO) Equal(t *DO) bool {
+	m.load()
+
 	if m.Size() != t.Size() {
 		return false
 	}
@@ -475,6 +707,10 @@ func (m *DO) Equal(t *DO) bool {
 			if mFloat != tFloat {
 				return false
 			}
+		case Number:
+			if !equalNumber(m.Map[i].(Number), t.Map[i].(Number)) {
+				return false
+			}
 		case *DO:
 			mdo := m.Map[i].(*DO)
 			tdo := t.Map[i].(*DO)
@@ -503,10 +739,16 @@ func (m *DO) Equal(t *DO) bool {
 }
 
 func (m *DO) Clone() *DO {
+	m.load()
 
 	t := NewDO()
 
 	t.Map = make(map[string]interface{})
+	t.ordered = m.ordered
+	t.coercion = m.coercion
+	if m.ordered {
+		t.keys = append([]string{}, m.orderedKeys()...)
+	}
 
 	for k := range m.Map {
 
@@ -520,10 +762,14 @@ func (m *DO) Clone() *DO {
 			t.Map[k] = m.Map[k].(string)
 		case bool:
 			t.Map[k] = m.Map[k].(bool)
-		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32:
 			t.Map[k], _ = m.Int(k)
+		case uint64:
+			t.Map[k] = m.Map[k].(uint64)
 		case float64:
 			t.Map[k], _ = m.Float(k)
+		case Number:
+			t.Map[k] = m.Map[k]
 		case *DO:
 			mdo := m.Map[k].(*DO)
 			t.Map[k] = mdo.Clone()
@@ -540,10 +786,27 @@ func (m *DO) Clone() *DO {
 }
 
 func (m *DO) Rename(from, to string) bool {
+	m.load()
+
 	if !m.HasKey(from) || from == to {
 		return false
 	}
 
+	if m.ordered {
+		keys := m.orderedKeys()
+		renamed := make([]string, 0, len(keys))
+		for _, k := range keys {
+			switch k {
+			case from:
+				renamed = append(renamed, to)
+			case to:
+			default:
+				renamed = append(renamed, k)
+			}
+		}
+		m.keys = renamed
+	}
+
 	m.Map[to] = m.Map[from]
 	delete(m.Map, from)
 
@@ -0,0 +1,95 @@
+package djson
+
+import (
+	"testing"
+
+	"github.com/goccy/go-json"
+)
+
+func TestOrderedParse(t *testing.T) {
+	jsonDoc := `{"name":"Ricardo Longa","idade":28,"skills":["Golang",{"zeta":1,"alpha":2}],"address":{"street":"a","city":"b"}}`
+
+	mJson := New().SetOrdered(true).Parse(jsonDoc)
+
+	if result := mJson.ToString(); result != jsonDoc {
+		t.Errorf("Expected %s, but got %s", jsonDoc, result)
+	}
+
+	keys := mJson.GetKeys()
+	expectedKeys := []string{"name", "idade", "skills", "address"}
+	for idx := range expectedKeys {
+		if keys[idx] != expectedKeys[idx] {
+			t.Errorf("Expected %v, but got %v", expectedKeys, keys)
+			break
+		}
+	}
+
+	if keys, _ := mJson.KeysPath(`["address"]`); keys[0] != "street" || keys[1] != "city" {
+		t.Errorf("Expected [street city], but got %v", keys)
+	}
+
+	expectedPretty := `{
+   "name": "Ricardo Longa",
+   "idade": 28,
+   "skills": [
+      "Golang",
+      {
+         "zeta": 1,
+         "alpha": 2
+      }
+   ],
+   "address": {
+      "street": "a",
+      "city": "b"
+   }
+}`
+	if result := mJson._Object.ToStringPretty(); result != expectedPretty {
+		t.Errorf("Expected %s, but got %s", expectedPretty, result)
+	}
+}
+
+func TestOrderedMutation(t *testing.T) {
+	obj := NewOrderedDO()
+	obj.Put("c", 1).Put("a", 2).Put("b", 3)
+	obj.Put("c", 4)
+
+	if result := obj.ToString(); result != `{"c":4,"a":2,"b":3}` {
+		t.Errorf(`Expected {"c":4,"a":2,"b":3}, but got %s`, result)
+	}
+
+	obj.Rename("a", "z")
+	obj.Remove("c")
+	obj.Put("y", Object{"k2": 1, "k1": 2})
+
+	expected := `{"z":2,"b":3,"y":{"k1":2,"k2":1}}`
+	if result := obj.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	clone := obj.Clone()
+	clone.Put("x", true)
+
+	expected = `{"z":2,"b":3,"y":{"k1":2,"k2":1},"x":true}`
+	if result := clone.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	obj.Map["direct"] = 1
+	if keys := obj.Keys(); len(keys) != 4 || keys[3] != "direct" {
+		t.Errorf("Expected direct as last key, but got %v", keys)
+	}
+}
+
+func TestPrettyMatchesMarshalIndent(t *testing.T) {
+	mJson := New().Put(Object{
+		"name":   "Ricardo Longa",
+		"empty":  Object{},
+		"list":   Array{},
+		"skills": Array{"Golang", 1, 2.5, Object{"a": nil}, Array{Array{1}}},
+	})
+
+	jsonByte, _ := json.MarshalIndent(ObjectToMap(mJson._Object), "", "   ")
+	if result := mJson._Object.ToStringPretty(); result != string(jsonByte) {
+		t.Errorf("Expected %s, but got %s", string(jsonByte), result)
+	}
+}
@@ -0,0 +1,428 @@
+package djson
+
+import (
+	"bytes"
+	"strings"
+)
+
+// formatSource is the text of a document parsed with PreserveFormat, kept in
+// step with the tree by the path mutators.
+type formatSource struct {
+	text   []byte
+	opts   ParseOptions
+	indent string
+}
+
+// sourceItem is one object member or array element in the source text.
+type sourceItem struct {
+	start      int // key for members, value for elements
+	keyEnd     int
+	valueStart int
+	valueEnd   int
+	comma      int // the following comma, or -1
+	key        string
+}
+
+// Source returns the document text. For a document parsed with
+// PreserveFormat it is the original text with the edits made through
+// UpdatePath, RemovePath, PutObjectToPath, PutArrayToPath and PushBackToPath
+// spliced in, so white space, key order and comments elsewhere stay as they
+// were. Edits made any other way are not reflected. Other documents return
+// ToString.
+
+func (m *JSON) Source() string {
+	if m._Source == nil {
+		return m.ToString()
+	}
+	return string(m._Source.text)
+}
+
+func newFormatSource(doc []byte, opts ParseOptions) *formatSource {
+	s := &formatSource{
+		text: append([]byte(nil), doc...),
+		opts: ParseOptions{Relaxed: opts.Relaxed, NonFinite: opts.NonFinite},
+	}
+	s.indent = s.detectIndent()
+	return s
+}
+
+// detectIndent returns the white space in front of the first indented line,
+// or two spaces.
+
+func (s *formatSource) detectIndent() string {
+	for _, line := range bytes.Split(s.text, []byte("\n"))[1:] {
+		trimmed := bytes.TrimLeft(line, " \t")
+		if len(trimmed) > 0 && len(trimmed) < len(line) {
+			return string(line[:len(line)-len(trimmed)])
+		}
+	}
+	return "  "
+}
+
+func (s *formatSource) parser(pos int) *parser {
+	p := newParser(s.text)
+	p.opts = s.opts
+	p.pos = pos
+	return p
+}
+
+// locate returns the position of the value at the path tokens.
+
+func (s *formatSource) locate(tokens []interface{}) (int, bool) {
+	p := s.parser(0)
+	p.skipSpace()
+
+	for _, token := range tokens {
+		items, _, err := p.scanItems()
+		if err != nil {
+			return 0, false
+		}
+
+		found := -1
+		switch t := token.(type) {
+		case string:
+			if s.text[p.pos] != '{' {
+				return 0, false
+			}
+			for idx := range items {
+				if items[idx].key == t {
+					found = idx
+				}
+			}
+		case int:
+			if s.text[p.pos] == '[' && t >= 0 && t < len(items) {
+				found = t
+			}
+		}
+
+		if found < 0 {
+			return 0, false
+		}
+		p.pos = items[found].valueStart
+	}
+
+	return p.pos, true
+}
+
+// scanItems reads the object or array at p.pos and returns its items and the
+// position of its closing bracket. p.pos is left at the opening bracket.
+
+func (p *parser) scanItems() ([]sourceItem, int, error) {
+	open := p.pos
+	if open >= len(p.data) || (p.data[open] != '{' && p.data[open] != '[') {
+		return nil, 0, p.errorAt(open, "%s where a container was expected", p.describe(open))
+	}
+
+	closing := byte(']')
+	if p.data[open] == '{' {
+		closing = '}'
+	}
+
+	defer func() { p.pos = open }()
+	p.pos++
+
+	var items []sourceItem
+	for {
+		p.skipSpace()
+		if p.pos < len(p.data) && p.data[p.pos] == closing {
+			return items, p.pos, nil
+		}
+
+		item := sourceItem{start: p.pos, comma: -1}
+		if closing == '}' {
+			key, err := p.parseKey()
+			if err != nil {
+				return nil, 0, err
+			}
+			item.key, item.keyEnd = key, p.pos
+
+			p.skipSpace()
+			if p.pos >= len(p.data) || p.data[p.pos] != ':' {
+				return nil, 0, p.errorAt(p.pos, "%s after object key", p.describe(p.pos))
+			}
+			p.pos++
+			p.skipSpace()
+		}
+
+		item.valueStart = p.pos
+		if _, err := p.parseValue(); err != nil {
+			return nil, 0, err
+		}
+		item.valueEnd = p.pos
+
+		p.skipSpace()
+		if p.pos < len(p.data) && p.data[p.pos] == ',' {
+			item.comma = p.pos
+			p.pos++
+		}
+		items = append(items, item)
+
+		if item.comma < 0 {
+			p.skipSpace()
+			if p.pos >= len(p.data) || p.data[p.pos] != closing {
+				return nil, 0, p.errorAt(p.pos, "%s in container", p.describe(p.pos))
+			}
+			return items, p.pos, nil
+		}
+	}
+}
+
+func (s *formatSource) splice(start, end int, text string) {
+	s.text = append(s.text[:start:start], append([]byte(text), s.text[end:]...)...)
+}
+
+// lineIndent returns the white space that starts the line holding pos.
+
+func (s *formatSource) lineIndent(pos int) string {
+	start := bytes.LastIndexByte(s.text[:pos], '\n') + 1
+	end := start
+	for end < len(s.text) && (s.text[end] == ' ' || s.text[end] == '\t') {
+		end++
+	}
+	return string(s.text[start:end])
+}
+
+// lineStart returns the start of the line holding pos when only white space
+// precedes pos on it, or pos.
+
+func (s *formatSource) lineStart(pos int) int {
+	start := pos
+	for start > 0 && (s.text[start-1] == ' ' || s.text[start-1] == '\t') {
+		start--
+	}
+	if start == 0 || s.text[start-1] == '\n' {
+		return start
+	}
+	return pos
+}
+
+// lineEnd returns the position after the line break that ends the line at
+// pos when only white space or a line comment follows pos on it, or limit.
+
+func (s *formatSource) lineEnd(pos, limit int) int {
+	for pos < limit && (s.text[pos] == ' ' || s.text[pos] == '\t' || s.text[pos] == '\r') {
+		pos++
+	}
+	if bytes.HasPrefix(s.text[pos:limit], []byte("//")) {
+		if i := bytes.IndexByte(s.text[pos:limit], '\n'); i >= 0 {
+			pos += i
+		}
+	}
+	if pos < limit && s.text[pos] == '\n' {
+		return pos + 1
+	}
+	return limit
+}
+
+// render encodes v for a container spanning open..closing, indenting nested
+// lines from indent when the container is written over several lines.
+
+func (s *formatSource) render(v interface{}, open, closing int, indent string) string {
+	if bytes.IndexByte(s.text[open:closing], '\n') < 0 {
+		return encodeToString(v, "")
+	}
+	return strings.ReplaceAll(encodeToString(v, s.indent), "\n", "\n"+indent)
+}
+
+// separator returns the text to put between two items of the container.
+
+func (s *formatSource) separator(items []sourceItem, open int) string {
+	seg := string(s.text[open+1 : items[0].start])
+	if len(items) > 1 {
+		seg = string(s.text[items[0].comma+1 : items[1].start])
+	}
+
+	if strings.Contains(seg, "\n") {
+		return "\n" + s.lineIndent(items[len(items)-1].start)
+	}
+	if strings.TrimSpace(seg) != "" {
+		return " "
+	}
+	return seg
+}
+
+func (s *formatSource) memberText(key string, v interface{}, items []sourceItem, open, closing int, indent string) string {
+	colon := ":"
+	if len(items) > 0 {
+		last := items[len(items)-1]
+		colon = string(s.text[last.keyEnd:last.valueStart])
+	}
+	return string(appendQuoted(nil, key)) + colon + s.render(v, open, closing, indent)
+}
+
+// insert places text before item idx, or after the last item when idx is
+// len(items).
+
+func (s *formatSource) insert(items []sourceItem, open, closing, idx int, text string) {
+	if len(items) == 0 {
+		s.splice(closing, closing, text)
+		return
+	}
+
+	sep := s.separator(items, open)
+	if idx < len(items) {
+		s.splice(items[idx].start, items[idx].start, text+","+sep)
+		return
+	}
+
+	last := items[len(items)-1]
+	if last.comma >= 0 {
+		s.splice(last.comma+1, last.comma+1, sep+text+",")
+		return
+	}
+	s.splice(last.valueEnd, last.valueEnd, ","+sep+text)
+}
+
+func (s *formatSource) remove(items []sourceItem, open, closing, idx int) {
+	item := items[idx]
+
+	switch {
+	case len(items) == 1:
+		s.splice(open+1, closing, "")
+	case idx < len(items)-1:
+		start, end := item.start, s.lineEnd(item.comma+1, items[idx+1].start)
+		if end < items[idx+1].start {
+			start = s.lineStart(start)
+		}
+		s.splice(start, end, "")
+	case item.comma >= 0:
+		s.splice(items[idx-1].comma+1, item.comma+1, "")
+	default:
+		start := item.start
+		for start > 0 && strings.IndexByte(" \t\r\n", s.text[start-1]) >= 0 {
+			start--
+		}
+		s.splice(start, item.valueEnd, "")
+		prev := items[idx-1].comma
+		s.splice(prev, prev+1, "")
+	}
+}
+
+// sourceEdit kinds
+const (
+	sourceSet = iota
+	sourceInsert
+	sourceRemove
+)
+
+// editSource applies to the text the edit that was just made to the tree at
+// the path tokens. When the text cannot follow, it is rewritten from the tree.
+
+func (m *JSON) editSource(kind int, tokens ...interface{}) {
+	if m._Source == nil {
+		return
+	}
+
+	if !m._Source.edit(m, tokens, kind) {
+		s := m._Source
+		s.text = []byte(encodeToString(m.Interface(), s.indent))
+	}
+}
+
+// pushBackSource appends the last element of the array at path to the text.
+
+func (m *JSON) pushBackSource(path string) {
+	if m._Source == nil {
+		return
+	}
+
+	size := 0
+	m.DoPathFunc(path, nil,
+		func(da *DA, idx int, _ interface{}) {
+			if arr, ok := da.Array(idx); ok {
+				size = arr.Size()
+			}
+		},
+		func(do *DO, key string, _ interface{}) {
+			if arr, ok := do.Array(key); ok {
+				size = arr.Size()
+			}
+		},
+	)
+
+	if size > 0 {
+		m.editSource(sourceInsert, append(PathTokenizer(path), size-1)...)
+	}
+}
+
+func (s *formatSource) edit(m *JSON, tokens []interface{}, kind int) bool {
+	if len(tokens) == 0 {
+		return false
+	}
+
+	open, ok := s.locate(tokens[:len(tokens)-1])
+	if !ok {
+		return false
+	}
+
+	items, closing, err := s.parser(open).scanItems()
+	if err != nil {
+		return false
+	}
+
+	var v interface{}
+	var exists bool
+	size := -1
+	m.doPathFuncCore(
+		func(da *DA, idx int, _ interface{}) { v, exists = da.Get(idx); size = da.Size() },
+		func(do *DO, key string, _ interface{}) { v, exists = do.Get(key) },
+		nil, tokens...)
+
+	idx := -1
+	switch t := tokens[len(tokens)-1].(type) {
+	case string:
+		if s.text[open] != '{' {
+			return false
+		}
+		for i := range items {
+			if items[i].key == t {
+				idx = i
+			}
+		}
+
+		switch {
+		case idx >= 0 && (kind == sourceRemove || !exists):
+			s.remove(items, open, closing, idx)
+		case idx >= 0:
+			item := items[idx]
+			s.splice(item.valueStart, item.valueEnd, s.render(v, open, closing, s.lineIndent(item.start)))
+		case kind != sourceRemove && exists:
+			indent := s.lineIndent(closing) + s.indent
+			if len(items) > 0 {
+				indent = s.lineIndent(items[len(items)-1].start)
+			}
+			s.insert(items, open, closing, len(items), s.memberText(t, v, items, open, closing, indent))
+		}
+	case int:
+		if s.text[open] != '[' {
+			return false
+		}
+		if kind == sourceInsert && (t < 0 || t > len(items)) {
+			t = len(items)
+		}
+		if kind == sourceSet && size != len(items) {
+			return false // UpdatePath padded the array; render it from the tree
+		}
+		if t < 0 || t > len(items) || (kind != sourceRemove && !exists) {
+			return kind != sourceInsert
+		}
+
+		indent := s.lineIndent(closing) + s.indent
+		if len(items) > 0 {
+			indent = s.lineIndent(items[0].start)
+		}
+
+		switch {
+		case kind == sourceRemove:
+			if t < len(items) {
+				s.remove(items, open, closing, t)
+			}
+		case kind == sourceInsert:
+			s.insert(items, open, closing, t, s.render(v, open, closing, indent))
+		default:
+			s.splice(items[t].valueStart, items[t].valueEnd, s.render(v, open, closing, indent))
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,171 @@
+package djson
+
+import (
+	"testing"
+)
+
+const preserveDoc = `{
+    "name":  "djson",
+    "version": 2,
+    "tags": ["json", "go"],
+    "deps": {
+        "null": "v8",
+        "errors": "v1"
+    }
+}
+`
+
+func parsePreserved(t *testing.T, doc string, relaxed bool) *JSON {
+	t.Helper()
+
+	mJson, err := New().ParseWithOptions([]byte(doc), ParseOptions{PreserveFormat: true, Relaxed: relaxed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mJson
+}
+
+func TestPreserveFormatUpdate(t *testing.T) {
+	mJson := parsePreserved(t, preserveDoc, false)
+
+	if result := mJson.Source(); result != preserveDoc {
+		t.Fatalf("Expected the original text, but got %s", result)
+	}
+
+	mJson.UpdatePath(`[version]`, 3)
+	mJson.UpdatePath(`[tags][1]`, "golang")
+	mJson.UpdatePath(`[deps][errors]`, Object{"major": 1})
+
+	expected := `{
+    "name":  "djson",
+    "version": 3,
+    "tags": ["json", "golang"],
+    "deps": {
+        "null": "v8",
+        "errors": {
+            "major": 1
+        }
+    }
+}
+`
+	if result := mJson.Source(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestPreserveFormatPadding(t *testing.T) {
+	mJson := parsePreserved(t, `{"list": [1, 2], "n": 1}`, false)
+
+	mJson.UpdatePath(`["list"][5]`, 7)
+	if result := New().Parse(mJson.Source()).ToString(); result != `{"list":[1,2,0,0,0],"n":1}` {
+		t.Errorf("Expected the text to follow the padded tree, but got %s", mJson.Source())
+	}
+
+	mJson = parsePreserved(t, `{"list": [1, 2], "n": 1}`, false)
+	mJson.UpdatePath(`["list"][1]`, 7)
+	if result := mJson.Source(); result != `{"list": [1, 7], "n": 1}` {
+		t.Errorf("Expected an in-range update to keep the layout, but got %s", result)
+	}
+}
+
+func TestPreserveFormatInsert(t *testing.T) {
+	mJson := parsePreserved(t, preserveDoc, false)
+
+	mJson.UpdatePath(`[license]`, "MIT")
+	mJson.PutObjectToPath(`[deps][yaml]`, "version", "v3")
+	mJson.PutArrayToPath(`[tags][0]`, "lib")
+	mJson.PushBackToPath(`[tags]`, "x")
+
+	expected := `{
+    "name":  "djson",
+    "version": 2,
+    "tags": [["lib"], "json", "go", "x"],
+    "deps": {
+        "null": "v8",
+        "errors": "v1",
+        "yaml": {
+            "version": "v3"
+        }
+    },
+    "license": "MIT"
+}
+`
+	if result := mJson.Source(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result, expected := mJson.Source(), mJson.ToString(); New().Parse(result).ToString() != expected {
+		t.Errorf("Expected the source to match the tree %s, but got %s", expected, result)
+	}
+}
+
+func TestPreserveFormatRemove(t *testing.T) {
+	mJson := parsePreserved(t, preserveDoc, false)
+
+	mJson.RemovePath(`[name]`)
+	mJson.RemovePath(`[tags][1]`)
+	mJson.RemovePath(`[deps][errors]`)
+
+	expected := `{
+    "version": 2,
+    "tags": ["json"],
+    "deps": {
+        "null": "v8"
+    }
+}
+`
+	if result := mJson.Source(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	mJson.RemovePath(`[deps][null]`)
+	mJson.RemovePath(`[tags][0]`)
+
+	expected = `{
+    "version": 2,
+    "tags": [],
+    "deps": {}
+}
+`
+	if result := mJson.Source(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestPreserveFormatRelaxed(t *testing.T) {
+	doc := `// settings
+{
+  port: 8080, // default
+  // hosts to serve
+  hosts: ['a', 'b',],
+  debug: false,
+}
+`
+	mJson := parsePreserved(t, doc, true)
+
+	mJson.RemovePath(`[port]`)
+	mJson.UpdatePath(`[debug]`, true)
+	mJson.PushBackToPath(`[hosts]`, "c")
+	mJson.UpdatePath(`[level]`, "info")
+
+	expected := `// settings
+{
+  // hosts to serve
+  hosts: ['a', 'b', "c",],
+  debug: true,
+  "level": "info",
+}
+`
+	if result := mJson.Source(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestPreserveFormatDisabled(t *testing.T) {
+	mJson := New().Parse(preserveDoc)
+	mJson.UpdatePath(`[version]`, 3)
+
+	if result, expected := mJson.Source(), mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
@@ -0,0 +1,171 @@
+package djson
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+)
+
+// SQLScanner returns an sql.Scanner that reads a JSON or JSONB column into m.
+// A NULL column gives a NULL document. JSON itself is not an sql.Scanner,
+// because its Scan method is the array iterator, so pass SQLScanner to Scan.
+//
+//	err := row.Scan(&id, doc.SQLScanner())
+
+func (m *JSON) SQLScanner() sql.Scanner {
+	return jsonScanner{m}
+}
+
+type jsonScanner struct {
+	m *JSON
+}
+
+func (s jsonScanner) Scan(src interface{}) error {
+	return s.m.scanSQL(src)
+}
+
+func (m *JSON) scanSQL(src interface{}) error {
+	switch t := src.(type) {
+	case nil:
+		*m = JSON{_Ordered: m._Ordered, _Lossless: m._Lossless, _Coercion: m._Coercion}
+		return nil
+	case []byte:
+		return m.UnmarshalJSON(t)
+	case string:
+		return m.UnmarshalJSON([]byte(t))
+	case int64, float64, bool:
+		*m = JSON{_Ordered: m._Ordered, _Lossless: m._Lossless, _Coercion: m._Coercion}
+		m.setElement(t)
+		return nil
+	}
+
+	return fmt.Errorf("djson: cannot scan %T into JSON", src)
+}
+
+// Value implements driver.Valuer. A NULL document is stored as a NULL column;
+// use NullJSON to store the JSON text null instead.
+
+func (m JSON) Value() (driver.Value, error) {
+	if m._Type == NULL {
+		return nil, nil
+	}
+
+	return m.MarshalJSON()
+}
+
+// NullJSON is a nullable document in the style of the null package: Valid is
+// false for a NULL column, while a valid NULL document is the JSON text null.
+type NullJSON struct {
+	JSON  *JSON
+	Valid bool
+}
+
+func NewNullJSON(j *JSON, valid bool) NullJSON {
+	return NullJSON{JSON: j, Valid: valid}
+}
+
+func NullJSONFrom(j *JSON) NullJSON {
+	return NewNullJSON(j, j != nil)
+}
+
+func NullJSONFromPtr(j **JSON) NullJSON {
+	if j == nil {
+		return NewNullJSON(nil, false)
+	}
+	return NullJSONFrom(*j)
+}
+
+func (n *NullJSON) SetValid(j *JSON) {
+	n.JSON = j
+	n.Valid = true
+}
+
+func (n NullJSON) Ptr() **JSON {
+	if !n.Valid {
+		return nil
+	}
+	return &n.JSON
+}
+
+func (n NullJSON) IsZero() bool {
+	return !n.Valid
+}
+
+// Interface returns the value Put stores for n: nil when n is not valid.
+
+func (n NullJSON) Interface() interface{} {
+	if !n.Valid || n.JSON == nil {
+		return nil
+	}
+	return n.JSON.Interface()
+}
+
+func (n NullJSON) MarshalJSON() ([]byte, error) {
+	if !n.Valid || n.JSON == nil {
+		return []byte("null"), nil
+	}
+	return n.JSON.MarshalJSON()
+}
+
+// UnmarshalJSON sets Valid to false for the JSON text null.
+
+func (n *NullJSON) UnmarshalJSON(data []byte) error {
+	j := New()
+	if err := j.UnmarshalJSON(data); err != nil {
+		return err
+	}
+
+	n.JSON, n.Valid = j, j._Type != NULL
+	if !n.Valid {
+		n.JSON = nil
+	}
+	return nil
+}
+
+func (n *NullJSON) Scan(src interface{}) error {
+	if src == nil {
+		n.JSON, n.Valid = nil, false
+		return nil
+	}
+
+	j := New()
+	if err := j.scanSQL(src); err != nil {
+		return err
+	}
+
+	n.JSON, n.Valid = j, true
+	return nil
+}
+
+func (n NullJSON) Value() (driver.Value, error) {
+	if !n.Valid {
+		return nil, nil
+	}
+
+	if n.JSON == nil {
+		return []byte("null"), nil
+	}
+	return n.JSON.MarshalJSON()
+}
+
+// FromRawJSON parses JSON text held in any []byte-based type, such as
+// sqlboiler's types.JSON or json.RawMessage. Empty input gives a NULL document.
+
+func FromRawJSON[T ~[]byte](raw T) (*JSON, error) {
+	m := New()
+	if len(raw) == 0 {
+		return m, nil
+	}
+
+	if err := m.scanSQL([]byte(raw)); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ToRawJSON returns the JSON text of m as any []byte-based type.
+
+func ToRawJSON[T ~[]byte](m *JSON) (T, error) {
+	b, err := m.MarshalJSON()
+	return T(b), err
+}
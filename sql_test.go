@@ -0,0 +1,113 @@
+package djson
+
+import (
+	stdjson "encoding/json"
+	"testing"
+
+	"github.com/volatiletech/null/v8"
+)
+
+type rawJSON []byte
+
+func TestJSONScanValue(t *testing.T) {
+	mJson := New()
+	if err := mJson.SQLScanner().Scan([]byte(`{"a":[1,"x"]}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := mJson.Value()
+	if err != nil || string(v.([]byte)) != `{"a":[1,"x"]}` {
+		t.Errorf("Expected {\"a\":[1,\"x\"]}, but got %v (%v)", v, err)
+	}
+
+	if err := mJson.SQLScanner().Scan(nil); err != nil || mJson.Type() != "null" {
+		t.Errorf("Expected NULL document, but got %s (%v)", mJson.Type(), err)
+	}
+
+	if v, _ := mJson.Value(); v != nil {
+		t.Errorf("Expected nil, but got %v", v)
+	}
+
+	if err := mJson.SQLScanner().Scan(`"text"`); err != nil || mJson.ToString() != "text" {
+		t.Errorf("Expected text, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	if err := mJson.SQLScanner().Scan(int64(3)); err != nil || mJson.Int() != 3 {
+		t.Errorf("Expected 3, but got %s (%v)", mJson.ToString(), err)
+	}
+
+	if err := mJson.SQLScanner().Scan(struct{}{}); err == nil {
+		t.Errorf("Expected error for unsupported source")
+	}
+}
+
+func TestJSONScanKeepsSettings(t *testing.T) {
+	mJson := New().SetCoercion(CoercionLenient)
+
+	for _, src := range []interface{}{nil, int64(3), []byte(`{"n":"4"}`)} {
+		if err := mJson.SQLScanner().Scan(src); err != nil || mJson.Coercion() != CoercionLenient {
+			t.Errorf("%v: Expected the coercion to be kept, but got %v (%v)", src, mJson.Coercion(), err)
+		}
+	}
+
+	if result, ok := mJson._Object.Int("n"); !ok || result != 4 {
+		t.Errorf("Expected the scanned object to follow the coercion, but got %d", result)
+	}
+}
+
+func TestNullJSON(t *testing.T) {
+	var n NullJSON
+	if err := n.Scan(nil); err != nil || n.Valid {
+		t.Errorf("Expected invalid NullJSON, but got %+v (%v)", n, err)
+	}
+
+	if v, _ := n.Value(); v != nil {
+		t.Errorf("Expected nil, but got %v", v)
+	}
+
+	if err := n.Scan([]byte(`null`)); err != nil || !n.Valid {
+		t.Errorf("Expected valid NullJSON, but got %+v (%v)", n, err)
+	}
+
+	if v, _ := n.Value(); string(v.([]byte)) != "null" {
+		t.Errorf("Expected null, but got %v", v)
+	}
+
+	var st struct {
+		A NullJSON `json:"a"`
+		B NullJSON `json:"b"`
+	}
+	if err := stdjson.Unmarshal([]byte(`{"a":{"k":1},"b":null}`), &st); err != nil {
+		t.Fatal(err)
+	}
+
+	if !st.A.Valid || st.A.JSON.Int("k") != 1 || st.B.Valid {
+		t.Errorf("Unexpected values %+v", st)
+	}
+
+	out, _ := stdjson.Marshal(st)
+	if string(out) != `{"a":{"k":1},"b":null}` {
+		t.Errorf("Expected {\"a\":{\"k\":1},\"b\":null}, but got %s", out)
+	}
+
+	mJson := New().Put(Object{"a": st.A, "b": st.B, "c": null.StringFrom("x")})
+	if result := mJson.ToString(); result != `{"a":{"k":1},"b":null,"c":"x"}` {
+		t.Errorf("Expected {\"a\":{\"k\":1},\"b\":null,\"c\":\"x\"}, but got %s", result)
+	}
+}
+
+func TestRawJSON(t *testing.T) {
+	mJson, err := FromRawJSON(rawJSON(`[1,2]`))
+	if err != nil || mJson.ToString() != `[1,2]` {
+		t.Errorf("Expected [1,2], but got %s (%v)", mJson.ToString(), err)
+	}
+
+	raw, err := ToRawJSON[rawJSON](mJson)
+	if err != nil || string(raw) != `[1,2]` {
+		t.Errorf("Expected [1,2], but got %s (%v)", raw, err)
+	}
+
+	if mJson, _ := FromRawJSON(stdjson.RawMessage(nil)); mJson.Type() != "null" {
+		t.Errorf("Expected NULL document, but got %s", mJson.Type())
+	}
+}
@@ -0,0 +1,158 @@
+package djson
+
+import (
+	"math"
+	"time"
+)
+
+// Special layouts for SetTimeLayout that store times as numbers.
+const (
+	TimeUnix      = "unix"      // seconds since the epoch, as INT
+	TimeUnixMilli = "unixmilli" // milliseconds since the epoch, as INT
+)
+
+// timeLayout is how time.Time values are stored by Put.
+var timeLayout = time.RFC3339Nano
+
+// SetTimeLayout sets how time.Time values put afterwards are stored: a
+// time.Format layout, TimeUnix or TimeUnixMilli. An empty layout restores
+// time.RFC3339Nano.
+
+func SetTimeLayout(layout string) {
+	if layout == "" {
+		layout = time.RFC3339Nano
+	}
+	timeLayout = layout
+}
+
+func encodeTime(t time.Time) interface{} {
+	switch timeLayout {
+	case TimeUnix:
+		return t.Unix()
+	case TimeUnixMilli:
+		return t.UnixMilli()
+	}
+
+	return t.Format(timeLayout)
+}
+
+// timeLayouts are tried after the configured layout when no layouts are
+// given to Time.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05",
+	"2006-01-02",
+}
+
+// decodeTime reads a string with the first matching layout, and a number as
+// unix time in milliseconds when TimeUnixMilli is among the layouts, or in
+// seconds otherwise.
+
+func decodeTime(v interface{}, layouts []string) (time.Time, bool) {
+	if len(layouts) == 0 {
+		layouts = append([]string{timeLayout}, timeLayouts...)
+	}
+
+	millis := false
+	for _, layout := range layouts {
+		if layout == TimeUnixMilli {
+			millis = true
+			break
+		}
+		if layout == TimeUnix {
+			break
+		}
+	}
+
+	var f float64
+	var ok bool
+
+	switch t := v.(type) {
+	case string:
+		for _, layout := range layouts {
+			if layout == TimeUnix || layout == TimeUnixMilli {
+				continue
+			}
+			if tm, err := time.Parse(layout, t); err == nil {
+				return tm, true
+			}
+		}
+		return time.Time{}, false
+	default:
+		if _, ok := v.(Number); !ok && !IsIntType(v) && !IsFloatType(v) {
+			return time.Time{}, false
+		}
+		if f, ok = getFloatBase(v); !ok {
+			return time.Time{}, false
+		}
+	}
+
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return time.Time{}, false
+	}
+
+	if millis {
+		return time.UnixMilli(int64(f)), true
+	}
+
+	sec, frac := math.Modf(f)
+	return time.Unix(int64(sec), int64(frac*1e9)), true
+}
+
+// Time reads the value at key as a time: a string in one of layouts, or a
+// unix timestamp. Without layouts the SetTimeLayout layout, RFC 3339 and a
+// few common date forms are tried.
+
+func (m *DO) Time(key string, layouts ...string) (time.Time, bool) {
+	m.load()
+
+	value, ok := m.Map[key]
+	if !ok {
+		return time.Time{}, false
+	}
+
+	return decodeTime(value, layouts)
+}
+
+func (m *DA) Time(idx int, layouts ...string) (time.Time, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return time.Time{}, false
+	}
+
+	return decodeTime(m.Element[idx], layouts)
+}
+
+func (m *JSON) Time(key interface{}, layouts ...string) (time.Time, bool) {
+	switch tkey := key.(type) {
+	case string:
+		if m._Type == OBJECT {
+			return m._Object.Time(tkey, layouts...)
+		}
+	default:
+		kint, ok := getIntBase(key)
+		if ok && m._Type == ARRAY {
+			return m._Array.Time(int(kint), layouts...)
+		}
+	}
+
+	return time.Time{}, false
+}
+
+func (m *JSON) TimePath(path string, layouts ...string) (time.Time, bool) {
+	var ret time.Time
+	var kok bool
+
+	pok := m.DoPathFunc(path, nil,
+		func(da *DA, idx int, v interface{}) {
+			ret, kok = da.Time(idx, layouts...)
+		},
+		func(do *DO, key string, v interface{}) {
+			ret, kok = do.Time(key, layouts...)
+		},
+	)
+
+	return ret, pok && kok
+}
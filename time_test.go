@@ -0,0 +1,124 @@
+package djson
+
+import (
+	"testing"
+	"time"
+
+	"github.com/volatiletech/null/v8"
+)
+
+func TestPutTime(t *testing.T) {
+	tm := time.Date(2024, 3, 1, 12, 30, 45, 500000000, time.UTC)
+
+	mJson := New().Put(Object{"at": tm, "list": Array{tm}})
+	mJson.Put("ptr", &tm)
+	mJson.Put("nt", null.TimeFrom(tm))
+	mJson.Put("invalid", null.Time{})
+
+	expected := `{"at":"2024-03-01T12:30:45.5Z","invalid":null,"list":["2024-03-01T12:30:45.5Z"],"nt":"2024-03-01T12:30:45.5Z","ptr":"2024-03-01T12:30:45.5Z"}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	for _, v := range []interface{}{tm, &tm, null.TimeFrom(tm)} {
+		if result := New().Put(v).ToString(); result != "2024-03-01T12:30:45.5Z" {
+			t.Errorf("%T: Expected a STRING document, but got %s", v, result)
+		}
+	}
+
+	var nilTime *time.Time
+	for _, v := range []interface{}{nilTime, null.Time{}} {
+		if result := New().Put(v); !result.IsNull() {
+			t.Errorf("%T: Expected a NULL document, but got %s", v, result.ToString())
+		}
+	}
+
+	if got, ok := mJson.Time("at"); !ok || !got.Equal(tm) {
+		t.Errorf("Expected %v, but got %v", tm, got)
+	}
+
+	if got, ok := mJson.TimePath(`[list][0]`); !ok || !got.Equal(tm) {
+		t.Errorf("Expected %v, but got %v", tm, got)
+	}
+
+	if _, ok := mJson.Time("invalid"); ok {
+		t.Errorf("Expected null not to be a time")
+	}
+}
+
+func TestTimeLayout(t *testing.T) {
+	tm := time.Date(2024, 3, 1, 12, 30, 45, 0, time.UTC)
+
+	SetTimeLayout(TimeUnix)
+	if result := New().Put(Object{"at": tm}).ToString(); result != `{"at":1709296245}` {
+		t.Errorf("Expected unix seconds, but got %s", result)
+	}
+
+	SetTimeLayout(TimeUnixMilli)
+	if result := New().Put(Object{"at": tm}).ToString(); result != `{"at":1709296245000}` {
+		t.Errorf("Expected unix milliseconds, but got %s", result)
+	}
+
+	SetTimeLayout("2006/01/02")
+	mJson := New().Put(Object{"at": tm})
+	SetTimeLayout("")
+
+	if result := mJson.ToString(); result != `{"at":"2024/03/01"}` {
+		t.Errorf("Expected a custom layout, but got %s", result)
+	}
+
+	if got, ok := mJson.Time("at", "2006/01/02"); !ok || !got.Equal(tm.Truncate(24*time.Hour)) {
+		t.Errorf("Expected a custom layout to parse, but got %v", got)
+	}
+
+	mJson = New().Parse(`{"sec":1709296245,"ms":1709296245000,"frac":1709296245.5,"date":"2024-03-01","local":"2024-03-01 12:30:45","word":"soon"}`)
+
+	if got, ok := mJson.Time("sec"); !ok || !got.Equal(tm) {
+		t.Errorf("Expected unix seconds to parse, but got %v", got)
+	}
+	if got, ok := mJson.Time("ms", TimeUnixMilli); !ok || !got.Equal(tm) {
+		t.Errorf("Expected unix milliseconds to parse, but got %v", got)
+	}
+	if got, ok := mJson.Time("frac"); !ok || !got.Equal(tm.Add(500*time.Millisecond)) {
+		t.Errorf("Expected fractional seconds to parse, but got %v", got)
+	}
+	if got, ok := mJson.Time("date"); !ok || !got.Equal(tm.Truncate(24*time.Hour)) {
+		t.Errorf("Expected a date to parse, but got %v", got)
+	}
+	if got, ok := mJson.Time("local"); !ok || !got.Equal(tm) {
+		t.Errorf("Expected a date time to parse, but got %v", got)
+	}
+	if _, ok := mJson.Time("word"); ok {
+		t.Errorf("Expected a word not to parse")
+	}
+}
+
+func TestTimeFields(t *testing.T) {
+	type Event struct {
+		Name    string     `json:"name"`
+		At      time.Time  `json:"at"`
+		Ends    *time.Time `json:"ends"`
+		Checked null.Time  `json:"checked"`
+		Missing null.Time  `json:"missing"`
+	}
+
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	ends := at.Add(time.Hour)
+
+	in := Event{Name: "launch", At: at, Ends: &ends, Checked: null.TimeFrom(at)}
+
+	mJson := New()
+	mJson.FromFields(in)
+
+	expected := `{"at":"2024-03-01T12:00:00Z","checked":"2024-03-01T12:00:00Z","ends":"2024-03-01T13:00:00Z","missing":null,"name":"launch"}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	var out Event
+	mJson.ToFields(&out)
+
+	if out.Name != "launch" || !out.At.Equal(at) || out.Ends == nil || !out.Ends.Equal(ends) || !out.Checked.Valid || !out.Checked.Time.Equal(at) || out.Missing.Valid {
+		t.Errorf("Expected %v, but got %v", in, out)
+	}
+}
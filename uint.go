@@ -0,0 +1,177 @@
+package djson
+
+import (
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// setUint stores u as an INT. Values above MaxInt64 keep their bits in _Int
+// and are flagged unsigned, so they survive a round trip instead of wrapping.
+
+func (m *JSON) setUint(u uint64) {
+	m._Object = nil
+	m._Array = nil
+	m._Number = ""
+	m._Int = int64(u)
+	m._Unsigned = u > math.MaxInt64
+	m._Type = INT
+}
+
+func getUintBase(v interface{}) (uint64, bool) {
+	switch t := v.(type) {
+	case Number:
+		if u, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			return u, true
+		}
+		f, err := t.Float64()
+		if err != nil || f < 0 || f >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(f), true
+	case uint, uint8, uint16, uint32, uint64, uintptr:
+		return reflect.ValueOf(t).Uint(), true
+	case int, int8, int16, int32, int64:
+		i := reflect.ValueOf(t).Int()
+		return uint64(i), i >= 0
+	case float32, float64:
+		f := reflect.ValueOf(t).Float()
+		if f < 0 || f >= math.MaxUint64 {
+			return 0, false
+		}
+		return uint64(f), true
+	case string:
+		u, err := strconv.ParseUint(t, 10, 64)
+		return u, err == nil
+	}
+
+	return 0, false
+}
+
+func toStrictUint(v interface{}) (uint64, bool) {
+	if n, ok := v.(Number); ok {
+		u, err := strconv.ParseUint(string(n), 10, 64)
+		return u, err == nil
+	}
+
+	if !IsIntType(v) {
+		return 0, false
+	}
+	return getUintBase(v)
+}
+
+func (m *DO) Uint(key string) (uint64, bool) {
+	m.load()
+
+	value, ok := m.Map[key]
+	if !ok {
+		return 0, false
+	}
+
+	if m.coercion != CoercionDefault {
+		return coerceUint(value, m.coercion)
+	}
+
+	return getUintBase(value)
+}
+
+func (m *DA) Uint(idx int) (uint64, bool) {
+	m.load()
+
+	if idx >= m.Size() || idx < 0 {
+		return 0, false
+	}
+
+	if m.coercion != CoercionDefault {
+		return coerceUint(m.Element[idx], m.coercion)
+	}
+
+	return getUintBase(m.Element[idx])
+}
+
+// Uint is the unsigned counterpart of Int. Negative values read as 0, or as
+// the default when one is given.
+
+func (m *JSON) Uint(key ...interface{}) uint64 {
+	if m._Coercion != CoercionDefault {
+		return coerced(m, key, coerceUint)
+	}
+
+	if IsEmptyArg(key) {
+
+		switch m._Type {
+		case INT:
+			if m._Number != "" {
+				u, _ := getUintBase(m._Number)
+				return u
+			}
+			if m._Unsigned || m._Int >= 0 {
+				return uint64(m._Int)
+			}
+			return 0
+		case BOOL:
+			if m._Bool {
+				return 1
+			}
+		case STRING, FLOAT:
+			u, _ := getUintBase(m.Interface())
+			return u
+		}
+
+		return 0
+	}
+
+	var dv uint64
+
+	if len(key) >= 2 {
+		if v, ok := getUintBase(key[1]); ok {
+			dv = v
+		}
+	}
+
+	switch tkey := key[0].(type) {
+	case string:
+		if m._Type == OBJECT {
+			if uVal, ok := m._Object.Uint(tkey); ok {
+				return uVal
+			}
+		}
+	default:
+		kint, ok := getIntBase(key[0])
+		if ok && m._Type == ARRAY {
+			if uVal, ok := m._Array.Uint(int(kint)); ok {
+				return uVal
+			}
+		}
+	}
+
+	return dv
+}
+
+func (m *JSON) UintPath(path string, dv ...uint64) uint64 {
+	if m._Coercion != CoercionDefault {
+		return coercedPath(m, path, dv, coerceUint)
+	}
+
+	var ret uint64
+	var kok bool
+
+	pok := m.DoPathFunc(path, nil,
+		func(da *DA, idx int, v interface{}) {
+			ret, kok = da.Uint(idx)
+		},
+		func(do *DO, key string, v interface{}) {
+			ret, kok = do.Uint(key)
+		},
+	)
+
+	if pok && kok {
+		return ret
+	}
+
+	if len(dv) > 0 {
+		return dv[0]
+	}
+
+	return 0
+}
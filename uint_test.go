@@ -0,0 +1,93 @@
+package djson
+
+import (
+	"math"
+	"testing"
+
+	"github.com/volatiletech/null/v8"
+)
+
+const maxUintStr = "18446744073709551615"
+
+func TestPutUint(t *testing.T) {
+	var id uint64 = math.MaxUint64
+
+	mJson := New().Put(Object{"id": id, "ids": Array{id, uint64(7)}})
+
+	expected := `{"id":18446744073709551615,"ids":[18446744073709551615,7]}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if result := New().Put(id).ToString(); result != maxUintStr {
+		t.Errorf("Expected %s, but got %s", maxUintStr, result)
+	}
+
+	if result := mJson.Uint("id"); result != id {
+		t.Errorf("Expected %d, but got %d", id, result)
+	}
+	if result := mJson.UintPath(`[ids][0]`); result != id {
+		t.Errorf("Expected %d, but got %d", id, result)
+	}
+	if result := mJson.Int("id", -1); result != -1 {
+		t.Errorf("Expected the default, but got %d", result)
+	}
+}
+
+func TestParseUint(t *testing.T) {
+	doc := `{"id":` + maxUintStr + `,"neg":-1}`
+
+	for _, mJson := range []*JSON{New().Parse(doc), New().Parse(doc).Clone()} {
+		if result := mJson.ToString(); result != doc {
+			t.Errorf("Expected %s, but got %s", doc, result)
+		}
+		if result := mJson.Uint("id"); result != math.MaxUint64 {
+			t.Errorf("Expected %s, but got %d", maxUintStr, result)
+		}
+		if result := mJson.Uint("neg", 3); result != 3 {
+			t.Errorf("Expected the default, but got %d", result)
+		}
+	}
+
+	scalar := New().Parse(maxUintStr)
+	if result := scalar.Uint(); result != math.MaxUint64 {
+		t.Errorf("Expected %s, but got %d", maxUintStr, result)
+	}
+	if result := scalar.ToString(); result != maxUintStr {
+		t.Errorf("Expected %s, but got %s", maxUintStr, result)
+	}
+
+	if v, err := Get[uint64](New().Parse(doc), `[id]`); err != nil || v != math.MaxUint64 {
+		t.Errorf("Expected %s, but got %d (%v)", maxUintStr, v, err)
+	}
+}
+
+func TestUintEqual(t *testing.T) {
+	a := New().Put(uint64(math.MaxUint64))
+	b := New().Parse(maxUintStr)
+	c := New().Put(int64(-1))
+
+	if !a.Equal(b) {
+		t.Errorf("Expected %s to equal %s", a.ToString(), b.ToString())
+	}
+	if a.Equal(c) {
+		t.Errorf("Expected %s not to equal %s", a.ToString(), c.ToString())
+	}
+}
+
+func TestUintToFields(t *testing.T) {
+	type record struct {
+		ID     uint64      `json:"id"`
+		Parent null.Uint64 `json:"parent"`
+	}
+
+	var r record
+	New().Parse(`{"id":` + maxUintStr + `,"parent":18446744073709551614}`).ToFields(&r)
+
+	if r.ID != math.MaxUint64 {
+		t.Errorf("Expected %s, but got %d", maxUintStr, r.ID)
+	}
+	if !r.Parent.Valid || r.Parent.Uint64 != math.MaxUint64-1 {
+		t.Errorf("Expected 18446744073709551614, but got %v", r.Parent)
+	}
+}
@@ -3,7 +3,9 @@ package djson
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -22,13 +24,37 @@ type supportedType interface {
 }
 
 func MapToObject(dmap map[string]interface{}) *DO {
-	nObj := NewDO()
+	return mapToObject(dmap, orderedObject)
+}
+
+// Go maps have no order, so an ordered object built from one takes its keys sorted by name.
+
+func mapToObject(dmap map[string]interface{}, ordered bool) *DO {
+	nObj := NewDO().SetOrdered(ordered)
+
+	if ordered {
+		for _, k := range sortedMapKeys(dmap) {
+			nObj.Put(k, dmap[k])
+		}
+		return nObj
+	}
+
 	for k, v := range dmap {
 		nObj.Put(k, v)
 	}
 	return nObj
 }
 
+func sortedMapKeys(dmap map[string]interface{}) []string {
+	keys := make([]string, 0, len(dmap))
+	for k := range dmap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
 func SliceToArray(dslice []interface{}) *DA {
 	nArr := NewDA()
 	nArr.Put(dslice)
@@ -47,6 +73,7 @@ func ObjectToMap(obj *DO) map[string]interface{} {
 	if obj == nil {
 		return wMap
 	}
+	obj.load()
 
 	for k, v := range obj.Map {
 		switch t := v.(type) {
@@ -58,6 +85,8 @@ func ObjectToMap(obj *DO) map[string]interface{} {
 			wMap[k] = ArrayToSlice(t)
 		case *DO:
 			wMap[k] = ObjectToMap(t)
+		case Number:
+			wMap[k] = json.Number(t)
 		default:
 			wMap[k] = v
 		}
@@ -73,6 +102,7 @@ func ArrayToSlice(arr *DA) []interface{} {
 	if arr == nil {
 		return wArray
 	}
+	arr.load()
 
 	for idx := range arr.Element {
 		switch t := arr.Element[idx].(type) {
@@ -84,6 +114,8 @@ func ArrayToSlice(arr *DA) []interface{} {
 			wArray = append(wArray, ArrayToSlice(t))
 		case *DO:
 			wArray = append(wArray, ObjectToMap(t))
+		case Number:
+			wArray = append(wArray, json.Number(t))
 		default:
 			wArray = append(wArray, t)
 		}
@@ -97,6 +129,10 @@ func getStringBase(v interface{}) (string, bool) {
 		return "nil", true
 	}
 
+	if n, ok := v.(Number); ok {
+		return string(n), true
+	}
+
 	if IsInTypes(v, "string", "bool", "float32", "float64") {
 		return fmt.Sprintf("%v", v), true
 	}
@@ -109,6 +145,13 @@ func getStringBase(v interface{}) (string, bool) {
 }
 
 func getBoolBase(v interface{}) (bool, bool) {
+	if n, ok := v.(Number); ok {
+		if r, ok := n.Rat(); ok && r.Sign() == 0 {
+			return false, true
+		}
+		return false, false
+	}
+
 	if IsInTypes(v, "int", "uint", "int8", "uint8", "int16", "uint16", "int32", "uint32", "int64", "uint64") {
 		intVal, _ := gov.ToInt(v)
 		if intVal == 0 {
@@ -136,6 +179,11 @@ func getBoolBase(v interface{}) (bool, bool) {
 }
 
 func getFloatBase(v interface{}) (float64, bool) {
+	if n, ok := v.(Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
 	if floatVal, err := gov.ToFloat(v); err != nil {
 		return 0, false
 	} else {
@@ -144,6 +192,23 @@ func getFloatBase(v interface{}) (float64, bool) {
 }
 
 func getIntBase(v interface{}) (int64, bool) {
+	if n, ok := v.(Number); ok {
+		if n.IsInt() {
+			i, err := n.Int64()
+			return i, err == nil
+		}
+
+		f, err := n.Float64()
+		if err != nil || f < math.MinInt64 || f >= math.MaxInt64 {
+			return 0, false
+		}
+		return int64(f), true
+	}
+
+	if u, ok := v.(uint64); ok && u > math.MaxInt64 {
+		return 0, false
+	}
+
 	if intVal, err := gov.ToInt(v); err != nil {
 		return 0, false
 	} else {
@@ -194,31 +259,37 @@ func IsInTypes(v interface{}, types ...string) bool {
 	return false
 }
 
-func ParseToObject(doc string) (*DO, error) {
-	var data map[string]interface{}
-
-	d := json.NewDecoder(strings.NewReader(doc))
-	d.UseNumber()
+// ParseToObject and ParseToArray use the same parser as Parse, so they
+// accept exactly the documents Parse accepts; anything after the value, as in
+// {"a":1} trailing, is an error.
 
-	if err := d.Decode(&data); err != nil {
+func ParseToObject(doc string) (*DO, error) {
+	obj, ok := parseStandalone(doc).(*DO)
+	if !ok {
 		return nil, errors.New("not Object")
 	}
 
-	return ParseObject(data), nil
-
+	return obj, nil
 }
 
 func ParseToArray(doc string) (*DA, error) {
-	var data []interface{}
-
-	d := json.NewDecoder(strings.NewReader(doc))
-	d.UseNumber()
-
-	if err := d.Decode(&data); err != nil {
+	arr, ok := parseStandalone(doc).(*DA)
+	if !ok {
 		return nil, errors.New("not Array")
 	}
 
-	return ParseArray(data), nil
+	return arr, nil
+}
+
+func parseStandalone(doc string) interface{} {
+	p := newParser([]byte(doc))
+	p.opts.Lossless = losslessNumber
+
+	v, err := p.parseDocument()
+	if err != nil {
+		return nil
+	}
+	return v
 }
 
 func ParseObject(data map[string]interface{}) *DO {
@@ -1,6 +1,8 @@
 package djson
 
 // o = object djson
+//
+// Deprecated: use GetSlice[string], which reports missing keys and wrong types.
 func MustGetStringSlice(o *JSON, key interface{}) []string {
 	emptyArraySlice := make([]string, 0)
 	if o == nil || key == "" {
@@ -14,6 +16,7 @@ func MustGetStringSlice(o *JSON, key interface{}) []string {
 	return JsonToStringSlice(r)
 }
 
+// Deprecated: use GetSlice[int64], which reports missing keys and wrong types.
 func MustGetInt64Slice(o *JSON, key interface{}) []int64 {
 	emptyArraySlice := make([]int64, 0)
 	if o == nil || key == "" {
@@ -27,6 +30,7 @@ func MustGetInt64Slice(o *JSON, key interface{}) []int64 {
 	return JsonToInt64Slice(r)
 }
 
+// Deprecated: use GetSlice[int], which reports missing keys and wrong types.
 func MustGetIntSlice(o *JSON, key interface{}) []int {
 	emptyArraySlice := make([]int, 0)
 	if o == nil || key == "" {
@@ -81,6 +85,8 @@ func IntSliceToJson(ss []int) *JSON {
 }
 
 // js must be array json
+//
+// Deprecated: use GetSlice[string].
 func JsonToStringSlice(js *JSON, key ...string) []string {
 	if js == nil || !js.IsArray() || js.Size() == 0 {
 		return []string{}
@@ -106,6 +112,7 @@ func ArrayJsonToStringSlice(js *JSON, key ...string) []string {
 	return JsonToStringSlice(js, key...)
 }
 
+// Deprecated: use GetSlice[int].
 func JsonToIntSlice(js *JSON, key ...string) []int {
 	if js == nil || !js.IsArray() || js.Size() == 0 {
 		return []int{}
@@ -127,6 +134,7 @@ func JsonToIntSlice(js *JSON, key ...string) []int {
 	return ss
 }
 
+// Deprecated: use GetSlice[int64].
 func JsonToInt64Slice(js *JSON, key ...string) []int64 {
 	if js == nil || !js.IsArray() || js.Size() == 0 {
 		return []int64{}
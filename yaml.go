@@ -0,0 +1,1545 @@
+package djson
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// YAML support covers what configuration files and manifests use: block and
+// flow collections, all scalar styles, multi-document streams, anchors,
+// aliases and << merge keys. Plain scalars are typed by the YAML 1.2 core
+// schema, so "yes" stays a string while 0x1F is a number. .inf, -.inf and
+// .nan are stored as NonFiniteNull and NonFiniteString say and are a
+// *SyntaxError wrapping ErrNonFiniteFloat otherwise. Aliases may copy at most
+// 100000 nodes into a document; beyond that parsing fails with a *SyntaxError
+// wrapping ErrMaxAliasNodes.
+// Explicit ? keys and complex (collection) keys are not supported.
+
+var (
+	ErrMultipleDocuments = errors.New("djson: YAML stream holds more than one document")
+	ErrMaxAliasNodes     = errors.New("djson: YAML aliases expand to too many nodes")
+)
+
+// yamlMaxAliasNodes caps the nodes aliases may copy into one document, so a
+// few nested anchors cannot expand a small input into gigabytes.
+const yamlMaxAliasNodes = 100000
+
+var (
+	yamlIntRe   = regexp.MustCompile(`^[-+]?[0-9]+$`)
+	yamlFloatRe = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlOctRe   = regexp.MustCompile(`^0o[0-7]+$`)
+	yamlHexRe   = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
+)
+
+type yamlParser struct {
+	lines   []string
+	offsets []int
+	n       int
+	end     int
+	anchors map[string]interface{}
+	aliased int
+
+	ordered  bool
+	lossless bool
+}
+
+func newYAMLParser(doc string) *yamlParser {
+	doc = strings.TrimPrefix(doc, "\ufeff")
+
+	p := &yamlParser{
+		lines: strings.Split(doc, "\n"),
+	}
+
+	offset := 0
+	p.offsets = make([]int, len(p.lines))
+	for i, l := range p.lines {
+		p.offsets[i] = offset
+		offset += len(l) + 1
+		p.lines[i] = strings.TrimSuffix(l, "\r")
+	}
+
+	return p
+}
+
+// ParseYAML parses a single YAML document into m.
+// Use ParseYAMLStream for input holding several documents.
+
+func (m *JSON) ParseYAML(doc string) (*JSON, error) {
+	if m._Type != NULL {
+		return m, ErrNotNull
+	}
+
+	p := newYAMLParser(doc)
+	p.ordered = m._Ordered
+	p.lossless = m._Lossless || losslessNumber
+
+	docs, err := p.documents()
+	if err != nil {
+		return m, err
+	}
+
+	if len(docs) > 1 {
+		return m, ErrMultipleDocuments
+	}
+
+	if len(docs) == 1 {
+		m.setElement(docs[0])
+	}
+
+	return m, nil
+}
+
+// ParseYAMLStream returns one *JSON per document of a --- separated stream.
+
+func ParseYAMLStream(doc string) ([]*JSON, error) {
+	p := newYAMLParser(doc)
+	p.lossless = losslessNumber
+
+	docs, err := p.documents()
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*JSON, len(docs))
+	for i := range docs {
+		ret[i] = New()
+		ret[i].setElement(docs[i])
+	}
+
+	return ret, nil
+}
+
+func (p *yamlParser) errorAt(n, col int, format string, args ...interface{}) *SyntaxError {
+	if n >= len(p.lines) {
+		n = len(p.lines) - 1
+		col = len(p.lines[n])
+	}
+
+	line := p.lines[n]
+	if col > len(line) {
+		col = len(line)
+	}
+
+	return &SyntaxError{
+		Msg:     fmt.Sprintf(format, args...),
+		Offset:  int64(p.offsets[n] + col),
+		Line:    n + 1,
+		Column:  utf8.RuneCountInString(line[:col]) + 1,
+		Snippet: strings.TrimSpace(line),
+	}
+}
+
+func (p *yamlParser) newObject() *DO {
+	obj := NewDO()
+	if p.ordered {
+		obj.SetOrdered(true)
+	}
+	return obj
+}
+
+func isYAMLMarker(line, marker string) bool {
+	return strings.HasPrefix(line, marker) && (len(line) == 3 || line[3] == ' ' || line[3] == '\t')
+}
+
+func isYAMLBlank(line string) bool {
+	t := strings.TrimSpace(line)
+	return t == "" || t[0] == '#'
+}
+
+func isYAMLSeqEntry(s string) bool {
+	return s == "-" || strings.HasPrefix(s, "- ") || strings.HasPrefix(s, "-\t")
+}
+
+func yamlIndent(line string) int {
+	i := 0
+	for i < len(line) && line[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// stripYAMLComment cuts a # comment off plain text.
+
+func stripYAMLComment(s string) string {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && (i == 0 || s[i-1] == ' ' || s[i-1] == '\t') {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+func (p *yamlParser) documents() ([]interface{}, error) {
+	docs := make([]interface{}, 0, 1)
+
+	for i := 0; i < len(p.lines); {
+		for i < len(p.lines) && (isYAMLBlank(p.lines[i]) || strings.HasPrefix(p.lines[i], "%")) {
+			i++
+		}
+		if i >= len(p.lines) {
+			break
+		}
+
+		if isYAMLMarker(p.lines[i], "...") {
+			i++
+			continue
+		}
+
+		if isYAMLMarker(p.lines[i], "---") {
+			p.lines[i] = "   " + p.lines[i][3:]
+		}
+
+		j := i + 1
+		for j < len(p.lines) && !isYAMLMarker(p.lines[j], "---") && !isYAMLMarker(p.lines[j], "...") {
+			j++
+		}
+
+		p.n, p.end = i, j
+		p.anchors = make(map[string]interface{})
+		p.aliased = 0
+
+		v, err := p.parseBlock(0, -1)
+		if err != nil {
+			return nil, err
+		}
+
+		p.skipBlank()
+		if p.n < p.end {
+			return nil, p.errorAt(p.n, yamlIndent(p.lines[p.n]), "unexpected content")
+		}
+
+		docs = append(docs, v)
+		i = j
+	}
+
+	return docs, nil
+}
+
+func (p *yamlParser) skipBlank() {
+	for p.n < p.end && isYAMLBlank(p.lines[p.n]) {
+		p.n++
+	}
+}
+
+func (p *yamlParser) indentAt(n int) (int, error) {
+	ind := yamlIndent(p.lines[n])
+	if ind < len(p.lines[n]) && p.lines[n][ind] == '\t' {
+		return 0, p.errorAt(n, ind, "tab character in indentation")
+	}
+	return ind, nil
+}
+
+// parseBlock parses the node on the current line when it is indented by at
+// least min; continuation lines must be indented deeper than parent.
+
+func (p *yamlParser) parseBlock(min, parent int) (interface{}, error) {
+	p.skipBlank()
+	if p.n >= p.end {
+		return nil, nil
+	}
+
+	ind, err := p.indentAt(p.n)
+	if err != nil || ind < min {
+		return nil, err
+	}
+
+	line := p.lines[p.n]
+	if isYAMLSeqEntry(line[ind:]) {
+		return p.parseSequence(ind)
+	}
+
+	if _, _, ok, err := p.splitKey(p.n, ind); err != nil {
+		return nil, err
+	} else if ok {
+		return p.parseMapping(ind)
+	}
+
+	return p.parseValue(ind, parent, false)
+}
+
+// parseNested parses the block node below a key or dash that had nothing
+// after it. A mapping value may be a sequence at the key's own indentation.
+
+func (p *yamlParser) parseNested(parent int, seqAtParent bool) (interface{}, error) {
+	p.skipBlank()
+	if p.n >= p.end {
+		return nil, nil
+	}
+
+	ind, err := p.indentAt(p.n)
+	if err != nil {
+		return nil, err
+	}
+
+	if seqAtParent && ind == parent && isYAMLSeqEntry(p.lines[p.n][ind:]) {
+		return p.parseSequence(ind)
+	}
+
+	if ind <= parent {
+		return nil, nil
+	}
+
+	return p.parseBlock(ind, parent)
+}
+
+func (p *yamlParser) parseSequence(ind int) (*DA, error) {
+	arr := NewDA()
+
+	for {
+		p.skipBlank()
+		if p.n >= p.end {
+			return arr, nil
+		}
+
+		li, err := p.indentAt(p.n)
+		if err != nil {
+			return nil, err
+		}
+
+		line := p.lines[p.n]
+		if li < ind || (li == ind && !isYAMLSeqEntry(line[li:])) {
+			return arr, nil
+		}
+		if li > ind {
+			return nil, p.errorAt(p.n, li, "bad indentation of a sequence entry")
+		}
+
+		col := ind + 1
+		for col < len(line) && (line[col] == ' ' || line[col] == '\t') {
+			col++
+		}
+
+		var v interface{}
+		if strings.TrimSpace(stripYAMLComment(line[col:])) == "" {
+			p.n++
+			v, err = p.parseNested(ind, false)
+		} else {
+			// blank out the dash so the entry parses like a block of its own
+			p.lines[p.n] = strings.Repeat(" ", col) + line[col:]
+			v, err = p.parseBlock(col, ind)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		arr.PutArray(v)
+	}
+}
+
+func (p *yamlParser) parseMapping(ind int) (*DO, error) {
+	obj := p.newObject()
+
+	for {
+		p.skipBlank()
+		if p.n >= p.end {
+			return obj, nil
+		}
+
+		li, err := p.indentAt(p.n)
+		if err != nil {
+			return nil, err
+		}
+
+		if li < ind {
+			return obj, nil
+		}
+		if li > ind {
+			return nil, p.errorAt(p.n, li, "bad indentation of a mapping entry")
+		}
+
+		key, col, ok, err := p.splitKey(p.n, ind)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return nil, p.errorAt(p.n, ind, "expected a mapping key")
+		}
+
+		v, err := p.parseValue(col, ind, true)
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "<<" {
+			if !mergeYAML(obj, v) {
+				return nil, p.errorAt(p.n-1, ind, "merge value must be a mapping or a sequence of mappings")
+			}
+			continue
+		}
+
+		obj.Put(key, v)
+	}
+}
+
+func mergeYAML(obj *DO, v interface{}) bool {
+	switch t := v.(type) {
+	case *DO:
+		for _, k := range t.Keys() {
+			if _, ok := obj.Map[k]; !ok {
+				obj.Put(k, t.Map[k])
+			}
+		}
+		return true
+	case *DA:
+		for _, each := range t.Element {
+			if !mergeYAML(obj, each) {
+				return false
+			}
+		}
+		return true
+	}
+
+	return false
+}
+
+// splitKey recognises "key: value" at column ind of line n and returns the
+// column where the value starts.
+
+func (p *yamlParser) splitKey(n, ind int) (string, int, bool, error) {
+	s := p.lines[n][ind:]
+
+	if strings.HasPrefix(s, "? ") || s == "?" {
+		return "", 0, false, p.errorAt(n, ind, "explicit mapping keys are not supported")
+	}
+
+	if s[0] == '"' || s[0] == '\'' {
+		end := findYAMLQuoteEnd(s, 1, s[0])
+		if end < 0 {
+			return "", 0, false, nil
+		}
+
+		k := end + 1
+		for k < len(s) && (s[k] == ' ' || s[k] == '\t') {
+			k++
+		}
+		if k >= len(s) || s[k] != ':' || (k+1 < len(s) && s[k+1] != ' ' && s[k+1] != '\t') {
+			return "", 0, false, nil
+		}
+
+		key, err := decodeYAMLQuoted(s[1:end], s[0])
+		if err != nil {
+			return "", 0, false, p.errorAt(n, ind, "%v", err)
+		}
+		return key, ind + k + 1, true, nil
+	}
+
+	if strings.IndexByte("[{#&*!|>%@`", s[0]) >= 0 || isYAMLSeqEntry(s) {
+		return "", 0, false, nil
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] == '#' && i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+			break
+		}
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t') {
+			return strings.TrimSpace(s[:i]), ind + i + 1, true, nil
+		}
+	}
+
+	return "", 0, false, nil
+}
+
+// parseValue parses the node that starts at column col of the current line
+// and belongs to a block indented by parent.
+
+func (p *yamlParser) parseValue(col, parent int, seqAtParent bool) (interface{}, error) {
+	line := p.lines[p.n]
+	skip := func() {
+		for col < len(line) && (line[col] == ' ' || line[col] == '\t') {
+			col++
+		}
+	}
+	skip()
+
+	anchor, tag := "", ""
+	for col < len(line) && (line[col] == '&' || line[col] == '!') {
+		end := col
+		for end < len(line) && line[end] != ' ' && line[end] != '\t' {
+			end++
+		}
+		if line[col] == '&' {
+			anchor = line[col+1 : end]
+		} else {
+			tag = line[col:end]
+		}
+		col = end
+		skip()
+	}
+
+	rest := line[col:]
+	at := p.n
+
+	var v interface{}
+	var err error
+
+	switch {
+	case strings.TrimSpace(stripYAMLComment(rest)) == "":
+		p.n++
+		v, err = p.parseNested(parent, seqAtParent)
+	case rest[0] == '*':
+		name := strings.TrimSpace(stripYAMLComment(rest[1:]))
+		a, ok := p.anchors[name]
+		if !ok {
+			return nil, p.errorAt(p.n, col, "unknown alias %q", name)
+		}
+		v, err = p.expand(a)
+		p.n++
+	case rest[0] == '|' || rest[0] == '>':
+		var s string
+		s, err = p.parseBlockScalar(col, parent)
+		if err == nil {
+			v, err = p.resolveTagged(tag, s, false)
+		}
+	case rest[0] == '[' || rest[0] == '{':
+		v, err = p.parseFlowBlock(col)
+	case rest[0] == '"' || rest[0] == '\'':
+		var s string
+		s, err = p.parseQuotedBlock(col)
+		if err == nil {
+			v, err = p.resolveTagged(tag, s, false)
+		}
+	default:
+		s := p.parsePlainBlock(col, parent)
+		if hasYAMLMappingIndicator(s) {
+			return nil, p.errorAt(at, col, "mapping values are not allowed in a plain scalar")
+		}
+		v, err = p.resolveTagged(tag, s, true)
+	}
+
+	var serr *SyntaxError
+	if err != nil && !errors.As(err, &serr) {
+		serr = p.errorAt(at, col, "%v", err)
+		serr.Err = err
+		return nil, serr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor != "" {
+		p.anchors[anchor] = v
+	}
+
+	return v, nil
+}
+
+// yamlValueError is a problem with a single node; the caller reports it as a
+// *SyntaxError at the node's position.
+type yamlValueError struct {
+	msg string
+	err error
+}
+
+func (e *yamlValueError) Error() string {
+	return e.msg
+}
+
+func (e *yamlValueError) Unwrap() error {
+	return e.err
+}
+
+// expand copies an anchored node for an alias, charging its size against
+// yamlMaxAliasNodes.
+
+func (p *yamlParser) expand(a interface{}) (interface{}, error) {
+	p.aliased += countYAML(a)
+	if p.aliased > yamlMaxAliasNodes {
+		return nil, &yamlValueError{fmt.Sprintf("aliases expand to more than %d nodes", yamlMaxAliasNodes), ErrMaxAliasNodes}
+	}
+	return cloneYAML(a), nil
+}
+
+func countYAML(v interface{}) int {
+	n := 1
+	switch t := v.(type) {
+	case *DO:
+		for _, each := range t.Map {
+			n += countYAML(each)
+		}
+	case *DA:
+		for _, each := range t.Element {
+			n += countYAML(each)
+		}
+	}
+	return n
+}
+
+func cloneYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case *DO:
+		return t.Clone()
+	case *DA:
+		return t.Clone()
+	}
+	return v
+}
+
+func (p *yamlParser) parsePlainBlock(col, parent int) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(stripYAMLComment(p.lines[p.n][col:])))
+	p.n++
+
+	blank := 0
+	for p.n < p.end {
+		l := p.lines[p.n]
+		t := strings.TrimSpace(l)
+		if t == "" {
+			blank++
+			p.n++
+			continue
+		}
+
+		if yamlIndent(l) <= parent || t[0] == '#' {
+			break
+		}
+
+		if _, _, key, _ := p.splitKey(p.n, yamlIndent(l)); key && parent >= 0 {
+			break // a deeper "key:" line is misindented, not more text
+		}
+
+		if blank > 0 {
+			b.WriteString(strings.Repeat("\n", blank))
+		} else {
+			b.WriteByte(' ')
+		}
+		b.WriteString(strings.TrimSpace(stripYAMLComment(t)))
+		blank = 0
+		p.n++
+	}
+
+	return b.String()
+}
+
+func (p *yamlParser) parseBlockScalar(col, parent int) (string, error) {
+	header := strings.TrimSpace(stripYAMLComment(p.lines[p.n][col:]))
+	folded := header[0] == '>'
+
+	chomp, explicit := byte(0), 0
+	for i := 1; i < len(header); i++ {
+		switch c := header[i]; {
+		case c == '-' || c == '+':
+			chomp = c
+		case c >= '1' && c <= '9':
+			explicit = int(c - '0')
+		default:
+			return "", p.errorAt(p.n, col+i, "invalid block scalar header %q", header)
+		}
+	}
+	p.n++
+
+	indent := -1
+	if explicit > 0 {
+		indent = explicit
+		if parent > 0 {
+			indent += parent
+		}
+	}
+
+	var lines []string
+	for p.n < p.end {
+		l := p.lines[p.n]
+		if strings.TrimSpace(l) == "" {
+			lines = append(lines, "")
+			p.n++
+			continue
+		}
+
+		li := yamlIndent(l)
+		if indent < 0 {
+			if li <= parent {
+				break
+			}
+			indent = li
+		}
+		if li < indent {
+			break
+		}
+
+		lines = append(lines, l[indent:])
+		p.n++
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	lines = lines[:len(lines)-trailing]
+
+	var b strings.Builder
+	pending, prevNormal := 0, false
+	for i, l := range lines {
+		if l == "" {
+			pending++
+			continue
+		}
+
+		normal := l[0] != ' ' && l[0] != '\t'
+		switch {
+		case i == pending: // leading blank lines
+			b.WriteString(strings.Repeat("\n", pending))
+		case folded && prevNormal && normal && pending == 0:
+			b.WriteByte(' ')
+		case folded && prevNormal && normal:
+			b.WriteString(strings.Repeat("\n", pending))
+		default:
+			b.WriteString(strings.Repeat("\n", pending+1))
+		}
+
+		b.WriteString(l)
+		pending, prevNormal = 0, normal
+	}
+
+	switch {
+	case chomp == '-':
+	case chomp == '+' && len(lines) > 0:
+		b.WriteString(strings.Repeat("\n", trailing+1))
+	case chomp == '+':
+		b.WriteString(strings.Repeat("\n", trailing))
+	case len(lines) > 0:
+		b.WriteByte('\n')
+	}
+
+	return b.String(), nil
+}
+
+func findYAMLQuoteEnd(s string, i int, quote byte) int {
+	for ; i < len(s); i++ {
+		switch {
+		case quote == '"' && s[i] == '\\':
+			i++
+		case s[i] == quote && quote == '\'' && i+1 < len(s) && s[i+1] == '\'':
+			i++
+		case s[i] == quote:
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *yamlParser) parseQuotedBlock(col int) (string, error) {
+	quote := p.lines[p.n][col]
+	start := p.n
+
+	var raw []string
+	from := col + 1
+	for {
+		line := p.lines[p.n]
+		if end := findYAMLQuoteEnd(line, from, quote); end >= 0 {
+			raw = append(raw, line[from:end])
+			if rest := strings.TrimSpace(stripYAMLComment(line[end+1:])); rest != "" {
+				return "", p.errorAt(p.n, end+1, "unexpected content after quoted scalar")
+			}
+			break
+		}
+
+		raw = append(raw, line[from:])
+		from = 0
+		p.n++
+		if p.n >= p.end {
+			return "", p.errorAt(start, col, "unterminated quoted scalar")
+		}
+	}
+	p.n++
+
+	s, err := decodeYAMLQuoted(strings.Join(raw, "\n"), quote)
+	if err != nil {
+		return "", p.errorAt(start, col, "%v", err)
+	}
+
+	return s, nil
+}
+
+// decodeYAMLQuoted folds line breaks and resolves escapes of the text
+// between the quotes.
+
+func decodeYAMLQuoted(raw string, quote byte) (string, error) {
+	if strings.IndexByte(raw, '\n') >= 0 {
+		segs := strings.Split(raw, "\n")
+
+		var b strings.Builder
+		b.WriteString(strings.TrimRight(segs[0], " \t"))
+
+		empty := 0
+		for i := 1; i < len(segs); i++ {
+			seg := strings.TrimLeft(segs[i], " \t")
+			if i < len(segs)-1 {
+				seg = strings.TrimRight(seg, " \t")
+				if seg == "" {
+					empty++
+					continue
+				}
+			}
+
+			cur := b.String()
+			switch {
+			case quote == '"' && empty == 0 && (len(cur)-len(strings.TrimRight(cur, "\\")))%2 == 1:
+				b.Reset()
+				b.WriteString(cur[:len(cur)-1]) // escaped line break
+			case empty > 0:
+				b.WriteString(strings.Repeat("\n", empty))
+			default:
+				b.WriteByte(' ')
+			}
+
+			b.WriteString(seg)
+			empty = 0
+		}
+		raw = b.String()
+	}
+
+	if quote == '\'' {
+		return strings.ReplaceAll(raw, "''", "'"), nil
+	}
+
+	if strings.IndexByte(raw, '\\') < 0 {
+		return raw, nil
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' {
+			b.WriteByte(raw[i])
+			continue
+		}
+
+		i++
+		if i >= len(raw) {
+			return "", errors.New("unterminated escape in quoted scalar")
+		}
+
+		size := 0
+		switch raw[i] {
+		case '0':
+			b.WriteByte(0)
+		case 'a':
+			b.WriteByte('\a')
+		case 'b':
+			b.WriteByte('\b')
+		case 't', '\t':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'v':
+			b.WriteByte('\v')
+		case 'f':
+			b.WriteByte('\f')
+		case 'r':
+			b.WriteByte('\r')
+		case 'e':
+			b.WriteByte(0x1b)
+		case ' ', '"', '/', '\\':
+			b.WriteByte(raw[i])
+		case 'N':
+			b.WriteRune('\u0085')
+		case '_':
+			b.WriteRune('\u00a0')
+		case 'L':
+			b.WriteRune('\u2028')
+		case 'P':
+			b.WriteRune('\u2029')
+		case 'x':
+			size = 2
+		case 'u':
+			size = 4
+		case 'U':
+			size = 8
+		default:
+			return "", fmt.Errorf("invalid escape %q in quoted scalar", raw[i-1:i+1])
+		}
+
+		if size > 0 {
+			if i+1+size > len(raw) {
+				return "", fmt.Errorf("short escape %q in quoted scalar", raw[i-1:])
+			}
+			r, err := strconv.ParseUint(raw[i+1:i+1+size], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape %q in quoted scalar", raw[i-1:i+1+size])
+			}
+			b.WriteRune(rune(r))
+			i += size
+		}
+	}
+
+	return b.String(), nil
+}
+
+func (p *yamlParser) resolveTagged(tag, s string, plain bool) (interface{}, error) {
+	switch tag {
+	case "!!str":
+		return s, nil
+	case "!!null":
+		return nil, nil
+	case "!!bool", "!!int", "!!float":
+		v := p.resolvePlain(s)
+		ok := false
+		switch v.(type) {
+		case bool:
+			ok = tag == "!!bool"
+		case int64, Number:
+			ok = tag == "!!int" || tag == "!!float"
+		case float64:
+			ok = tag == "!!float"
+		}
+		if !ok {
+			return nil, fmt.Errorf("%q is not a valid %s", s, tag)
+		}
+		return finiteYAML(v, s)
+	}
+
+	if plain {
+		return finiteYAML(p.resolvePlain(s), s)
+	}
+
+	return s, nil
+}
+
+// finiteYAML applies the NonFiniteFloatPolicy to .inf and .nan, which the
+// document would otherwise drop.
+
+func finiteYAML(v interface{}, s string) (interface{}, error) {
+	f, ok := v.(float64)
+	if !ok || !isNonFinite(f) {
+		return v, nil
+	}
+
+	if r, keep, _ := nonFiniteValue(f, nonFinitePolicy); keep {
+		return r, nil
+	}
+	return nil, &yamlValueError{"non-finite float " + s, ErrNonFiniteFloat}
+}
+
+// hasYAMLMappingIndicator reports a ": " in plain text, which would start a
+// mapping no block scalar may hold.
+
+func hasYAMLMappingIndicator(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == ':' && (i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\t' || s[i+1] == '\n') {
+			return true
+		}
+	}
+	return false
+}
+
+// resolvePlain types a plain scalar by the YAML 1.2 core schema.
+
+func (p *yamlParser) resolvePlain(s string) interface{} {
+	switch s {
+	case "", "~", "null", "Null", "NULL":
+		return nil
+	case "true", "True", "TRUE":
+		return true
+	case "false", "False", "FALSE":
+		return false
+	case ".inf", ".Inf", ".INF", "+.inf", "+.Inf", "+.INF":
+		return math.Inf(1)
+	case "-.inf", "-.Inf", "-.INF":
+		return math.Inf(-1)
+	case ".nan", ".NaN", ".NAN":
+		return math.NaN()
+	}
+
+	base, digits := 0, s
+	switch {
+	case yamlIntRe.MatchString(s):
+		base = 10
+	case yamlOctRe.MatchString(s):
+		base, digits = 8, s[2:]
+	case yamlHexRe.MatchString(s):
+		base, digits = 16, s[2:]
+	}
+
+	if base > 0 {
+		n, _ := new(big.Int).SetString(strings.TrimPrefix(digits, "+"), base)
+		switch {
+		case p.lossless:
+			return Number(n.String())
+		case n.IsInt64():
+			return n.Int64()
+		}
+		f, _ := new(big.Float).SetInt(n).Float64()
+		return f
+	}
+
+	if yamlFloatRe.MatchString(s) {
+		lexeme := strings.TrimPrefix(s, "+")
+		if p.lossless && isNumberLexeme(lexeme) {
+			return Number(lexeme)
+		}
+		f, _ := strconv.ParseFloat(lexeme, 64)
+		return f
+	}
+
+	return s
+}
+
+type yamlFlow struct {
+	p    *yamlParser
+	s    string
+	i    int
+	line int
+	col  int
+}
+
+func (p *yamlParser) parseFlowBlock(col int) (interface{}, error) {
+	text, endLine, endCol, err := p.scanFlow(col)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &yamlFlow{p: p, s: text, line: p.n, col: col}
+	v, err := f.node()
+	if err != nil {
+		return nil, err
+	}
+
+	if rest := strings.TrimSpace(stripYAMLComment(p.lines[endLine][endCol:])); rest != "" {
+		return nil, p.errorAt(endLine, endCol, "unexpected content after flow collection")
+	}
+
+	p.n = endLine + 1
+	return v, nil
+}
+
+// scanFlow collects the text of a flow collection, which may span lines,
+// up to its closing bracket with comments removed.
+
+func (p *yamlParser) scanFlow(col int) (string, int, int, error) {
+	var b strings.Builder
+
+	depth, quote, last := 0, byte(0), byte('[')
+	for n := p.n; n < p.end; n++ {
+		line := p.lines[n]
+		i, from := 0, 0
+		if n == p.n {
+			i, from = col, col
+		} else {
+			b.WriteByte('\n')
+		}
+
+		for ; i < len(line); i++ {
+			c := line[i]
+
+			if quote != 0 {
+				switch {
+				case quote == '"' && c == '\\':
+					i++
+				case c == quote && quote == '\'' && i+1 < len(line) && line[i+1] == '\'':
+					i++
+				case c == quote:
+					quote = 0
+				}
+				continue
+			}
+
+			switch c {
+			case ' ', '\t':
+				continue
+			case '"', '\'':
+				if strings.IndexByte("[{,:", last) >= 0 {
+					quote = c
+				}
+			case '#':
+				if i == 0 || line[i-1] == ' ' || line[i-1] == '\t' {
+					b.WriteString(line[from:i])
+					from = len(line)
+					i = len(line)
+					continue
+				}
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+				if depth == 0 {
+					b.WriteString(line[from : i+1])
+					return b.String(), n, i + 1, nil
+				}
+			}
+			last = c
+		}
+
+		if from < len(line) {
+			b.WriteString(line[from:])
+		}
+	}
+
+	return "", 0, 0, p.errorAt(p.n, col, "unterminated flow collection")
+}
+
+func (f *yamlFlow) errorf(format string, args ...interface{}) error {
+	return f.p.errorAt(f.line, f.col, format, args...)
+}
+
+func (f *yamlFlow) skipSpace() {
+	for f.i < len(f.s) && strings.IndexByte(" \t\n", f.s[f.i]) >= 0 {
+		f.i++
+	}
+}
+
+func (f *yamlFlow) token() string {
+	start := f.i
+	for f.i < len(f.s) && strings.IndexByte(" \t\n,[]{}", f.s[f.i]) < 0 {
+		f.i++
+	}
+	return f.s[start:f.i]
+}
+
+func (f *yamlFlow) node() (interface{}, error) {
+	f.skipSpace()
+	if f.i >= len(f.s) {
+		return nil, f.errorf("unexpected end of flow collection")
+	}
+
+	anchor, tag := "", ""
+	for f.i < len(f.s) && (f.s[f.i] == '&' || f.s[f.i] == '!') {
+		if t := f.token(); t[0] == '&' {
+			anchor = t[1:]
+		} else {
+			tag = t
+		}
+		f.skipSpace()
+	}
+
+	var v interface{}
+	var err error
+
+	switch c := f.s[f.i]; c {
+	case '[':
+		v, err = f.sequence()
+	case '{':
+		v, err = f.mapping()
+	case '*':
+		f.i++
+		name := f.token()
+		a, ok := f.p.anchors[name]
+		if !ok {
+			return nil, f.errorf("unknown alias %q", name)
+		}
+		v, err = f.p.expand(a)
+	case '"', '\'':
+		var s string
+		if s, err = f.quoted(); err == nil {
+			v, err = f.p.resolveTagged(tag, s, false)
+		}
+	default:
+		v, err = f.p.resolveTagged(tag, f.plain(), true)
+	}
+
+	var serr *SyntaxError
+	if err != nil && !errors.As(err, &serr) {
+		serr = f.p.errorAt(f.line, f.col, "%v", err)
+		serr.Err = err
+		return nil, serr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if anchor != "" {
+		f.p.anchors[anchor] = v
+	}
+
+	return v, nil
+}
+
+func (f *yamlFlow) quoted() (string, error) {
+	quote := f.s[f.i]
+	end := findYAMLQuoteEnd(f.s, f.i+1, quote)
+	if end < 0 {
+		return "", f.errorf("unterminated quoted scalar")
+	}
+
+	s, err := decodeYAMLQuoted(f.s[f.i+1:end], quote)
+	if err != nil {
+		return "", f.errorf("%v", err)
+	}
+
+	f.i = end + 1
+	return s, nil
+}
+
+func (f *yamlFlow) plain() string {
+	start := f.i
+	for f.i < len(f.s) {
+		c := f.s[f.i]
+		if strings.IndexByte(",[]{}", c) >= 0 {
+			break
+		}
+		if c == ':' && (f.i+1 == len(f.s) || strings.IndexByte(" \t\n,[]{}", f.s[f.i+1]) >= 0) {
+			break
+		}
+		f.i++
+	}
+
+	lines := strings.Split(f.s[start:f.i], "\n")
+	for i := range lines {
+		lines[i] = strings.TrimSpace(lines[i])
+	}
+
+	return strings.TrimSpace(strings.Join(lines, " "))
+}
+
+func (f *yamlFlow) key() (string, error) {
+	f.skipSpace()
+	if f.i < len(f.s) && (f.s[f.i] == '"' || f.s[f.i] == '\'') {
+		return f.quoted()
+	}
+	if f.i < len(f.s) && strings.IndexByte("[{", f.s[f.i]) >= 0 {
+		return "", f.errorf("complex mapping keys are not supported")
+	}
+	return f.plain(), nil
+}
+
+// value parses what follows a key: a node after ':' or nothing (null).
+
+func (f *yamlFlow) value(closing byte) (interface{}, error) {
+	f.skipSpace()
+	if f.i >= len(f.s) || f.s[f.i] != ':' {
+		return nil, nil
+	}
+
+	f.i++
+	f.skipSpace()
+	if f.i < len(f.s) && (f.s[f.i] == ',' || f.s[f.i] == closing) {
+		return nil, nil
+	}
+
+	return f.node()
+}
+
+func (f *yamlFlow) next(closing byte) (bool, error) {
+	f.skipSpace()
+	if f.i >= len(f.s) {
+		return false, f.errorf("unexpected end of flow collection")
+	}
+
+	switch f.s[f.i] {
+	case ',':
+		f.i++
+		return true, nil
+	case closing:
+		return true, nil
+	}
+
+	return false, f.errorf("expected ',' or '%c' in flow collection", closing)
+}
+
+func (f *yamlFlow) sequence() (*DA, error) {
+	f.i++ // '['
+	arr := NewDA()
+
+	for {
+		f.skipSpace()
+		if f.i < len(f.s) && f.s[f.i] == ']' {
+			f.i++
+			return arr, nil
+		}
+
+		save := f.i
+		v, err := f.node()
+		if err != nil {
+			return nil, err
+		}
+
+		f.skipSpace()
+		if f.i < len(f.s) && f.s[f.i] == ':' { // single pair mapping
+			f.i = save
+			key, err := f.key()
+			if err != nil {
+				return nil, err
+			}
+			val, err := f.value(']')
+			if err != nil {
+				return nil, err
+			}
+			obj := f.p.newObject()
+			obj.Put(key, val)
+			v = obj
+		}
+
+		arr.PutArray(v)
+
+		if ok, err := f.next(']'); !ok {
+			return nil, err
+		}
+	}
+}
+
+func (f *yamlFlow) mapping() (*DO, error) {
+	f.i++ // '{'
+	obj := f.p.newObject()
+
+	for {
+		f.skipSpace()
+		if f.i < len(f.s) && f.s[f.i] == '}' {
+			f.i++
+			return obj, nil
+		}
+
+		key, err := f.key()
+		if err != nil {
+			return nil, err
+		}
+
+		v, err := f.value('}')
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "<<" {
+			if !mergeYAML(obj, v) {
+				return nil, f.errorf("merge value must be a mapping or a sequence of mappings")
+			}
+		} else {
+			obj.Put(key, v)
+		}
+
+		if ok, err := f.next('}'); !ok {
+			return nil, err
+		}
+	}
+}
+
+// ToYAML renders the document as block-style YAML that ParseYAML reads back
+// to the same value.
+
+func (m *JSON) ToYAML() string {
+	var b strings.Builder
+
+	switch v := m.Interface().(type) {
+	case *DO:
+		writeYAMLMapping(&b, v, 0, false)
+	case *DA:
+		writeYAMLSequence(&b, v, 0, false)
+	default:
+		writeYAMLScalar(&b, v, 0)
+	}
+
+	return b.String()
+}
+
+func derefYAML(v interface{}) interface{} {
+	switch t := v.(type) {
+	case DO:
+		return &t
+	case DA:
+		return &t
+	}
+	return v
+}
+
+func isEmptyYAMLCollection(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case *DO:
+		if t.Len() == 0 {
+			return "{}", true
+		}
+		return "", false
+	case *DA:
+		if t.Size() == 0 {
+			return "[]", true
+		}
+		return "", false
+	}
+	return "", true
+}
+
+// writeYAMLMapping writes obj at indent. When inline is set the first key
+// continues the current line, as after a sequence dash.
+
+func writeYAMLMapping(b *strings.Builder, obj *DO, indent int, inline bool) {
+	if obj.Len() == 0 {
+		b.WriteString("{}\n")
+		return
+	}
+
+	for i, k := range obj.Keys() {
+		if i > 0 || !inline {
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+		b.WriteString(yamlString(k))
+		b.WriteByte(':')
+
+		v := derefYAML(obj.Map[k])
+		if _, flat := isEmptyYAMLCollection(v); !flat {
+			b.WriteByte('\n')
+			switch t := v.(type) {
+			case *DO:
+				writeYAMLMapping(b, t, indent+2, false)
+			case *DA:
+				writeYAMLSequence(b, t, indent+2, false)
+			}
+			continue
+		}
+
+		b.WriteByte(' ')
+		writeYAMLScalar(b, v, indent+2)
+	}
+}
+
+func writeYAMLSequence(b *strings.Builder, arr *DA, indent int, inline bool) {
+	if arr.Size() == 0 {
+		b.WriteString("[]\n")
+		return
+	}
+
+	for i, v := range arr.Element {
+		v = derefYAML(v)
+		if i > 0 || !inline {
+			b.WriteString(strings.Repeat(" ", indent))
+		}
+		b.WriteString("- ")
+
+		if _, flat := isEmptyYAMLCollection(v); !flat {
+			switch t := v.(type) {
+			case *DO:
+				writeYAMLMapping(b, t, indent+2, true)
+			case *DA:
+				writeYAMLSequence(b, t, indent+2, true)
+			}
+			continue
+		}
+
+		writeYAMLScalar(b, v, indent+2)
+	}
+}
+
+// writeYAMLScalar writes v and the line break; multi-line strings become
+// literal blocks indented by indent.
+
+func writeYAMLScalar(b *strings.Builder, v interface{}, indent int) {
+	switch t := v.(type) {
+	case *DO, *DA:
+		s, _ := isEmptyYAMLCollection(t)
+		b.WriteString(s)
+	case nil:
+		b.WriteString("null")
+	case string:
+		if header, ok := yamlLiteralHeader(t); ok {
+			b.WriteString(header)
+			for _, l := range strings.Split(strings.TrimSuffix(t, "\n"), "\n") {
+				b.WriteByte('\n')
+				if l != "" {
+					b.WriteString(strings.Repeat(" ", indent))
+					b.WriteString(l)
+				}
+			}
+		} else {
+			b.WriteString(yamlString(t))
+		}
+	case float32, float64:
+		f, _ := getFloatBase(t)
+		switch {
+		case math.IsNaN(f):
+			b.WriteString(".nan")
+		case math.IsInf(f, 1):
+			b.WriteString(".inf")
+		case math.IsInf(f, -1):
+			b.WriteString("-.inf")
+		default:
+			b.WriteString(encodeToString(t, ""))
+		}
+	default:
+		b.WriteString(encodeToString(t, ""))
+	}
+
+	b.WriteByte('\n')
+}
+
+// yamlLiteralHeader reports whether s reads better as a | block.
+
+func yamlLiteralHeader(s string) (string, bool) {
+	if !strings.Contains(strings.TrimSuffix(s, "\n"), "\n") || strings.HasSuffix(s, "\n\n") || s[0] == ' ' || s[0] == '\n' {
+		return "", false
+	}
+
+	for _, l := range strings.Split(s, "\n") {
+		if strings.TrimRight(l, " \t") != l {
+			return "", false
+		}
+		for _, r := range l {
+			if r == '\t' {
+				continue
+			}
+			if !unicode.IsPrint(r) || r == '\ufeff' {
+				return "", false
+			}
+		}
+	}
+
+	if strings.HasSuffix(s, "\n") {
+		return "|", true
+	}
+	return "|-", true
+}
+
+// yamlString returns s plain when that reads back as the same string,
+// double-quoted otherwise.
+
+func yamlString(s string) string {
+	plain := s != "" && s == strings.TrimSpace(s) &&
+		strings.IndexByte("-?:,[]{}#&*!|>'\"%@`", s[0]) < 0 && !strings.HasPrefix(s, "...") &&
+		!strings.Contains(s, ": ") && !strings.Contains(s, " #") && !strings.HasSuffix(s, ":")
+
+	if plain {
+		if _, ok := (&yamlParser{}).resolvePlain(s).(string); !ok {
+			plain = false
+		}
+	}
+
+	if plain {
+		switch strings.ToLower(s) {
+		case "y", "n", "yes", "no", "on", "off": // YAML 1.1 booleans
+			plain = false
+		}
+	}
+
+	if plain {
+		for _, r := range s {
+			if !unicode.IsPrint(r) || r == '\ufeff' {
+				plain = false
+				break
+			}
+		}
+	}
+
+	if plain {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == utf8.RuneError && size == 1:
+			b.WriteString("\ufffd")
+		case r == ' ' || unicode.IsPrint(r) && r != '\ufeff':
+			b.WriteRune(r)
+		case r > 0xffff:
+			fmt.Fprintf(&b, `\U%08x`, r)
+		default:
+			fmt.Fprintf(&b, `\u%04x`, r)
+		}
+	}
+	b.WriteByte('"')
+
+	return b.String()
+}
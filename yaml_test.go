@@ -0,0 +1,260 @@
+package djson
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParseYAML(t *testing.T) {
+	yamlDoc := `# deployment
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: "api-server"
+  labels: {app: api, tier: 'back end'}
+spec:
+  replicas: 3
+  paused: false
+  ratio: 0.75
+  selector: ~
+  ports: [8080, 0x1F91, 0o17]
+  containers:
+  - name: api
+    image: registry.local/api:1.2
+    args:
+      - --verbose
+      - - nested
+        - list
+    env:
+      - {name: MODE, value: prod}
+  script: |
+    echo one
+      indented
+    echo two
+  summary: >-
+    folded
+    text
+
+    new paragraph
+  empty:
+  yes: yes
+  url: http://example.com/a#b
+`
+
+	mJson, err := New().ParseYAML(yamlDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"apiVersion":"apps/v1","kind":"Deployment","metadata":{"labels":{"app":"api","tier":"back end"},"name":"api-server"},"spec":{"containers":[{"args":["--verbose",["nested","list"]],"env":[{"name":"MODE","value":"prod"}],"image":"registry.local/api:1.2","name":"api"}],"empty":null,"paused":false,"ports":[8080,8081,15],"ratio":0.75,"replicas":3,"script":"echo one\n  indented\necho two\n","selector":null,"summary":"folded text\nnew paragraph","url":"http://example.com/a#b","yes":"yes"}}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+}
+
+func TestParseYAMLAnchors(t *testing.T) {
+	yamlDoc := `
+base: &base
+  retries: 3
+  timeout: 10
+tags: &tags [a, b]
+prod:
+  <<: *base
+  timeout: 30
+  tags: *tags
+`
+
+	mJson, err := New().ParseYAML(yamlDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"base":{"retries":3,"timeout":10},"prod":{"retries":3,"tags":["a","b"],"timeout":30},"tags":["a","b"]}`
+	if result := mJson.ToString(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	if _, err := New().ParseYAML("a: *missing"); err == nil {
+		t.Errorf("Expected error for unknown alias")
+	}
+}
+
+func TestParseYAMLScalars(t *testing.T) {
+	cases := map[string]string{
+		`v: 12`:                          `{"v":12}`,
+		`v: -3.5e2`:                      `{"v":-350}`,
+		`v: "12"`:                        `{"v":"12"}`,
+		`v: !!str 12`:                    `{"v":"12"}`,
+		`v: TRUE`:                        `{"v":true}`,
+		`v: Null`:                        `{"v":null}`,
+		`v: 'it''s'`:                     `{"v":"it's"}`,
+		`v: "tab\there \u00e9 \x41"`:     `{"v":"tab\there é A"}`,
+		"v: \"line\n  folded\n\n  two\"": `{"v":"line folded\ntwo"}`,
+		"v: plain\n  continued":          `{"v":"plain continued"}`,
+		`"quoted key": 1`:                `{"quoted key":1}`,
+		`v: [a: 1, {b: c}]`:              `{"v":[{"a":1},{"b":"c"}]}`,
+	}
+
+	for doc, expected := range cases {
+		mJson, err := New().ParseYAML(doc)
+		if err != nil {
+			t.Errorf("%s: %v", doc, err)
+			continue
+		}
+
+		if result := mJson.ToString(); result != expected {
+			t.Errorf("%s: Expected %s, but got %s", doc, expected, result)
+		}
+	}
+
+	if mJson, _ := New().ParseYAML("42"); mJson.Int() != 42 {
+		t.Errorf("Expected 42, but got %s", mJson.ToString())
+	}
+}
+
+func TestParseYAMLNonFinite(t *testing.T) {
+	for _, doc := range []string{"v: .inf", "v: -.Inf", "- .nan", "v: !!float .inf", "v: [1, .inf]"} {
+		var serr *SyntaxError
+		if _, err := New().ParseYAML(doc); !errors.As(err, &serr) || !errors.Is(err, ErrNonFiniteFloat) {
+			t.Errorf("%q: Expected a *SyntaxError wrapping ErrNonFiniteFloat, but got %v", doc, err)
+		} else if strings.Count(err.Error(), "djson:") != 1 {
+			t.Errorf("%q: Expected a single prefix, but got %v", doc, err)
+		}
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteString)
+	defer SetNonFiniteFloatPolicy(NonFiniteDrop)
+
+	mJson, err := New().ParseYAML("a: .inf\nb: -.inf\nc: [.nan]")
+	if expected := `{"a":"Infinity","b":"-Infinity","c":["NaN"]}`; err != nil || mJson.ToString() != expected {
+		t.Errorf("Expected %s, but got %s (%v)", expected, mJson.ToString(), err)
+	}
+
+	SetNonFiniteFloatPolicy(NonFiniteNull)
+	if mJson, err := New().ParseYAML("a: .inf"); err != nil || mJson.ToString() != `{"a":null}` {
+		t.Errorf("Expected null, but got %s (%v)", mJson.ToString(), err)
+	}
+}
+
+func TestParseYAMLAliasLimit(t *testing.T) {
+	var b strings.Builder
+	b.WriteString("a: &a [x, x, x, x, x, x, x, x, x, x]\n")
+	for i := 'b'; i <= 'j'; i++ {
+		fmt.Fprintf(&b, "%c: &%c [", i, i)
+		for k := 0; k < 10; k++ {
+			if k > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "*%c", i-1)
+		}
+		b.WriteString("]\n")
+	}
+
+	_, err := New().ParseYAML(b.String())
+	var serr *SyntaxError
+	if !errors.As(err, &serr) || !errors.Is(err, ErrMaxAliasNodes) {
+		t.Errorf("Expected a *SyntaxError wrapping ErrMaxAliasNodes, but got %v", err)
+	}
+
+	mJson, err := New().ParseYAML("a: &a {x: 1}\nb: [*a, *a]")
+	if err != nil || mJson.ToString() != `{"a":{"x":1},"b":[{"x":1},{"x":1}]}` {
+		t.Errorf("Expected small aliases to expand, but got %s (%v)", mJson.ToString(), err)
+	}
+}
+
+func TestParseYAMLStream(t *testing.T) {
+	docs, err := ParseYAMLStream("%YAML 1.2\n---\na: 1\n---\n- x\n- y\n...\n--- plain\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{`{"a":1}`, `["x","y"]`, `plain`}
+	if len(docs) != len(expected) {
+		t.Fatalf("Expected %d documents, but got %d", len(expected), len(docs))
+	}
+
+	for i := range docs {
+		if result := docs[i].ToString(); result != expected[i] {
+			t.Errorf("Expected %s, but got %s", expected[i], result)
+		}
+	}
+
+	if _, err := New().ParseYAML("a: 1\n---\nb: 2\n"); !errors.Is(err, ErrMultipleDocuments) {
+		t.Errorf("Expected ErrMultipleDocuments, but got %v", err)
+	}
+}
+
+func TestParseYAMLErrors(t *testing.T) {
+	docs := []string{
+		"a: 1\n   b: 2",
+		"a:\n\t- 1",
+		"a: [1, 2",
+		"a: \"open",
+		"a: !!int abc",
+		"- a\nb: 1",
+		"? complex\n: key",
+		"a: b: c",
+		"- a: b: c",
+		"a: text\n  more: text",
+		"a: b:",
+	}
+
+	for _, doc := range docs {
+		_, err := New().ParseYAML(doc)
+		var serr *SyntaxError
+		if !errors.As(err, &serr) {
+			t.Errorf("%q: Expected *SyntaxError, but got %v", doc, err)
+		}
+	}
+}
+
+func TestToYAML(t *testing.T) {
+	mJson := New().Put(Object{
+		"name":    "Ricardo Longa",
+		"idade":   28,
+		"ratio":   0.5,
+		"active":  true,
+		"nothing": nil,
+		"number":  "28",
+		"yes":     "yes",
+		"quote":   "say \"hi\": now",
+		"script":  "echo one\necho two\n",
+		"empty":   Object{},
+		"skills":  Array{"Golang", Object{"name": "Android", "years": 3}, Array{1, 2}, Array{}},
+	})
+
+	expected := `active: true
+empty: {}
+idade: 28
+name: Ricardo Longa
+nothing: null
+number: "28"
+quote: "say \"hi\": now"
+ratio: 0.5
+script: |
+  echo one
+  echo two
+skills:
+  - Golang
+  - name: Android
+    years: 3
+  - - 1
+    - 2
+  - []
+"yes": "yes"
+`
+	if result := mJson.ToYAML(); result != expected {
+		t.Errorf("Expected %s, but got %s", expected, result)
+	}
+
+	back, err := New().ParseYAML(mJson.ToYAML())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result := back.ToString(); result != mJson.ToString() {
+		t.Errorf("Expected %s, but got %s", mJson.ToString(), result)
+	}
+}